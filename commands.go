@@ -0,0 +1,325 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	config "github.com/Haptic-Labs/tmux-sessionizer/config"
+	git "github.com/Haptic-Labs/tmux-sessionizer/git"
+)
+
+// command is a non-interactive subcommand invoked as `tmux-sessionizer <name> [args]`
+type command struct {
+	Name    string
+	Usage   string
+	Summary string
+	Run     func(args []string) error
+}
+
+// commands lists every available subcommand
+var commands = []command{
+	{
+		Name:    "convert",
+		Usage:   convertUsage,
+		Summary: "Convert the global (or repo) config file to another format",
+		Run:     runConvert,
+	},
+	{
+		Name:    "schema",
+		Usage:   schemaUsage,
+		Summary: "Print the JSON Schema for config files (for editor completion and validation)",
+		Run:     runSchema,
+	},
+	{
+		Name:    "explain",
+		Usage:   explainUsage,
+		Summary: "Explain which config, rule and profile apply to a repository",
+		Run:     runExplain,
+	},
+	{
+		Name:    "config",
+		Usage:   configUsage,
+		Summary: "List or edit the global (or repo) config without the interactive UI",
+		Run:     runConfig,
+	},
+	{
+		Name:    "trust",
+		Usage:   trustUsage,
+		Summary: "Review and trust a repository's committed .tmux-sessionizer config",
+		Run:     runTrust,
+	},
+	{
+		Name:    "untrust",
+		Usage:   untrustUsage,
+		Summary: "Revoke trust for a repository's committed .tmux-sessionizer config",
+		Run:     runUntrust,
+	},
+	{
+		Name:    "migrate-repo-configs",
+		Usage:   migrateUsage,
+		Summary: "Move .git-stored repo configs into the central store (repo_config_storage: central)",
+		Run:     runMigrateRepoConfigs,
+	},
+	{
+		Name:    "copy-config",
+		Usage:   copyConfigUsage,
+		Summary: "Copy a repo config from one repository to another",
+		Run:     runCopyConfig,
+	},
+	{
+		Name:    "apply-config",
+		Usage:   applyConfigUsage,
+		Summary: "Copy a repo config to every repository under --dir whose path matches a glob",
+		Run:     runApplyConfig,
+	},
+}
+
+// findCommand returns the subcommand with the given name, or nil
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].Name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// printUsage prints flag and subcommand help
+func printUsage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] [directory]\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(out, "       %s [flags] <command> [args]\n\n", filepath.Base(os.Args[0]))
+	fmt.Fprintln(out, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(out, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %s\n    \t%s\n", cmd.Usage, cmd.Summary)
+	}
+}
+
+// newCommandFlagSet creates a flag set for a subcommand that reports errors instead of exiting
+// usage is the command line synopsis, starting with the subcommand name
+func newCommandFlagSet(usage string) *flag.FlagSet {
+	name, _, _ := strings.Cut(usage, " ")
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s\n", filepath.Base(os.Args[0]), usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// resolveRepoDir turns a --repo argument into an absolute git repository path
+func resolveRepoDir(repo string) (string, error) {
+	absDir, err := filepath.Abs(repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if !git.IsGitRepo(absDir) {
+		return "", fmt.Errorf("%s is not a git repository", absDir)
+	}
+	return absDir, nil
+}
+
+const convertUsage = "convert [--repo <path>] <json|yaml|toml>"
+
+// repoDirArg resolves an optional path argument (default: current directory) to an absolute path
+func repoDirArg(args []string) (string, error) {
+	if len(args) > 1 {
+		return "", fmt.Errorf("expected at most one path")
+	}
+
+	path := "."
+	if len(args) == 1 {
+		path = args[0]
+	}
+	absDir, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	return absDir, nil
+}
+
+// runConvert converts a config file between JSON, YAML and TOML
+func runConvert(args []string) error {
+	fs := newCommandFlagSet(convertUsage)
+	repo := fs.String("repo", "", "Convert the repo-level config of this repository instead of the global config")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one target format")
+	}
+
+	format, err := config.ParseFormat(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var configPath string
+	if *repo != "" {
+		repoDir, err := resolveRepoDir(*repo)
+		if err != nil {
+			return err
+		}
+		configPath, err = config.GetRepoConfigPath(repoDir)
+		if err != nil {
+			return err
+		}
+	} else {
+		configPath, err = config.GetConfigPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return fmt.Errorf("no config file found at %s", configPath)
+	}
+
+	newPath, err := config.ConvertConfigFile(configPath, format)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Converted %s -> %s\n", configPath, newPath)
+	if *repo == "" && newPath != configPath && config.HasExplicitConfigFile() {
+		fmt.Printf("Note: update --config-file or $%s to point at the new file\n", config.ConfigFileEnv)
+	}
+	return nil
+}
+
+const schemaUsage = "schema"
+
+// runSchema prints the JSON Schema describing the config file format
+func runSchema(args []string) error {
+	fs := newCommandFlagSet(schemaUsage)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments")
+	}
+
+	schema, err := config.JSONSchema()
+	if err != nil {
+		return fmt.Errorf("failed to generate schema: %w", err)
+	}
+	fmt.Println(string(schema))
+	return nil
+}
+
+const explainUsage = "explain [path]"
+
+// runExplain prints how the config for a repository is resolved
+func runExplain(args []string) error {
+	fs := newCommandFlagSet(explainUsage)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	repoDir, err := repoDirArg(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	resolution := config.ResolveConfig(repoDir)
+
+	fmt.Printf("Path:    %s\n", repoDir)
+	switch {
+	case resolution.Path != "":
+		fmt.Printf("Source:  %s (%s)\n", resolution.Source, resolution.Path)
+	case resolution.Source == config.SourceDetected:
+		fmt.Printf("Source:  %s (built-in %s template)\n", resolution.Source, resolution.ProjectType)
+	default:
+		fmt.Printf("Source:  %s (no config file found)\n", resolution.Source)
+	}
+
+	// Host sections in the config are matched against this name
+	if hostname, err := config.Hostname(); err == nil {
+		fmt.Printf("Host:    %s\n", hostname)
+	}
+
+	if inTree := resolution.InTree; inTree != nil && resolution.Source != config.SourceInTree {
+		if inTree.Trusted {
+			fmt.Printf("In-tree: %s (trusted, but invalid or overridden)\n", inTree.Path)
+		} else {
+			fmt.Printf("In-tree: %s (not trusted; run `trust` to review it)\n", inTree.Path)
+		}
+	}
+
+	if resolution.Source != config.SourceRepo && resolution.Source != config.SourceInTree {
+		matches := resolution.Config.MatchRules(repoDir)
+		if len(matches) == 0 {
+			fmt.Println("Rules:   no rule matched")
+		} else {
+			fmt.Println("Rules:   (most specific first)")
+			for i, match := range matches {
+				marker := " "
+				if i == 0 {
+					marker = "*"
+				}
+				fmt.Printf("  %s [%d] %s\n", marker, match.Index, match.Rule)
+			}
+		}
+	}
+
+	if resolution.Source != config.SourceRepo && resolution.Source != config.SourceInTree && resolution.Config.AutoDetectEnabled() {
+		if projectType := config.DetectProjectType(repoDir); projectType != "" {
+			fmt.Printf("Project: %s\n", projectType)
+		} else {
+			fmt.Println("Project: no project type detected")
+		}
+	}
+
+	fmt.Printf("Profile: %s\n", resolution.Config.DefaultProfileOrDefault())
+	return nil
+}
+
+const migrateUsage = "migrate-repo-configs [directory]"
+
+// runMigrateRepoConfigs moves .git-stored repo configs under a directory into the central store
+func runMigrateRepoConfigs(args []string) error {
+	fs := newCommandFlagSet(migrateUsage)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	searchDir, err := repoDirArg(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfigStrict()
+	if err != nil {
+		return err
+	}
+	if cfg.RepoConfigStorage != config.RepoStorageCentral {
+		return fmt.Errorf("central storage is not enabled; run `config set repo_config_storage %s` first", config.RepoStorageCentral)
+	}
+
+	repos, err := git.FindGitRepos(searchDir)
+	if err != nil {
+		return fmt.Errorf("failed to find git repositories: %w", err)
+	}
+
+	migrated, failed := 0, 0
+	for _, repo := range repos {
+		newPath, err := config.MigrateRepoConfig(repo)
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "skipped %s: %v\n", repo, err)
+			failed++
+		case newPath != "":
+			fmt.Printf("migrated %s -> %s\n", repo, newPath)
+			migrated++
+		}
+	}
+
+	fmt.Printf("%d config(s) migrated, %d skipped\n", migrated, failed)
+	return nil
+}
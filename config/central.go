@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	git "github.com/Haptic-Labs/tmux-sessionizer/git"
+)
+
+// Repo config storage modes for Config.RepoConfigStorage
+const (
+	RepoStorageGit     = "git"     // .git/x-tmux-sessionizer in each repository (default)
+	RepoStorageCentral = "central" // the global config directory, keyed by origin remote URL
+)
+
+// storageCache holds the storage mode read from one version of the global config file, so
+// looking up many repositories' configs (e.g. for the picker's [configured] indicators)
+// doesn't parse the global config for each of them
+var storageCache struct {
+	sync.Mutex
+	path    string
+	modTime time.Time
+	size    int64
+	mode    string
+}
+
+// repoConfigStorage returns the storage mode configured in the global config
+func repoConfigStorage() string {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return RepoStorageGit
+	}
+	info, err := os.Stat(configPath)
+	if err != nil {
+		// No readable config file, so the defaults apply
+		return RepoStorageGit
+	}
+
+	storageCache.Lock()
+	defer storageCache.Unlock()
+	if storageCache.path != configPath || !storageCache.modTime.Equal(info.ModTime()) || storageCache.size != info.Size() {
+		cfg, _ := loadGlobalConfig()
+		storageCache.path = configPath
+		storageCache.modTime = info.ModTime()
+		storageCache.size = info.Size()
+		storageCache.mode = cfg.repoConfigStorageOrDefault()
+	}
+	return storageCache.mode
+}
+
+// repoConfigStorageOrDefault returns the config's storage mode, defaulting to git
+func (c *Config) repoConfigStorageOrDefault() string {
+	if c.RepoConfigStorage == "" {
+		return RepoStorageGit
+	}
+	return c.RepoConfigStorage
+}
+
+// validateRepoConfigStorage checks the repo_config_storage setting
+func (c *Config) validateRepoConfigStorage() error {
+	switch c.RepoConfigStorage {
+	case "", RepoStorageGit, RepoStorageCentral:
+		return nil
+	}
+	return fmt.Errorf("repo_config_storage must be %q or %q", RepoStorageGit, RepoStorageCentral)
+}
+
+// gitRepoConfigDir returns the in-repository location of a repo config
+func gitRepoConfigDir(repoDir string) string {
+	return filepath.Join(repoDir, ".git", "x-tmux-sessionizer")
+}
+
+// CentralRepoConfigDir returns the central store location for a repository's config,
+// keyed by its normalized origin remote URL
+// Returns false if the repository has no usable origin remote
+func CentralRepoConfigDir(repoDir string) (string, bool, error) {
+	remote := git.NormalizeRemoteURL(git.OriginURL(repoDir))
+	if remote == "" {
+		return "", false, nil
+	}
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", false, err
+	}
+
+	return filepath.Join(configDir, "repos", filepath.FromSlash(remote)), true, nil
+}
+
+// MigrateRepoConfig moves a repository's .git-stored config into the central store
+// Returns the new path, or an empty string if there was nothing to migrate
+func MigrateRepoConfig(repoDir string) (string, error) {
+	oldPath, found := findConfigFile(gitRepoConfigDir(repoDir), configFileBase)
+	if !found {
+		return "", nil
+	}
+
+	centralDir, ok, err := CentralRepoConfigDir(repoDir)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("no origin remote to key the config by")
+	}
+
+	if existing, found := findConfigFile(centralDir, configFileBase); found {
+		return "", fmt.Errorf("central config already exists at %s", existing)
+	}
+
+	// Validate before moving so a broken config isn't silently carried over
+	if _, err := readConfigFile(oldPath); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(oldPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read repo config: %w", err)
+	}
+
+	if err := os.MkdirAll(centralDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	newPath := filepath.Join(centralDir, filepath.Base(oldPath))
+	if err := writeFileAtomic(newPath, data, 0644); err != nil {
+		return "", err
+	}
+
+	if err := os.Remove(oldPath); err != nil {
+		return "", fmt.Errorf("config copied to %s but failed to remove %s: %w", newPath, oldPath, err)
+	}
+	// Remove the now-empty directory; ignore failure if other files remain
+	os.Remove(filepath.Dir(oldPath))
+
+	return newPath, nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// maxCommandHistory is the number of window commands remembered for the config editor
+const maxCommandHistory = 100
+
+// commandHistoryPath returns the file holding previously entered window commands
+func commandHistoryPath() (string, error) {
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "command_history.json"), nil
+}
+
+// LoadCommandHistory returns previously entered window commands, oldest first
+// A missing or unreadable history is treated as empty
+func LoadCommandHistory() []string {
+	path, err := commandHistoryPath()
+	if err != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var history []string
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil
+	}
+	return history
+}
+
+// AddCommandHistory records a window command as the most recent history entry
+// An earlier identical entry is moved rather than duplicated
+func AddCommandHistory(command string) error {
+	if command == "" {
+		return nil
+	}
+
+	path, err := commandHistoryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	unlock, err := lockDir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var history []string
+	for _, entry := range LoadCommandHistory() {
+		if entry != command {
+			history = append(history, entry)
+		}
+	}
+	history = append(history, command)
+	if len(history) > maxCommandHistory {
+		history = history[len(history)-maxCommandHistory:]
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal command history: %w", err)
+	}
+	return writeFileAtomic(path, data, 0600)
+}
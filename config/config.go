@@ -1,22 +1,53 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 )
 
 // WindowConfig represents a single window configuration
+// The If* conditions are optional; a window is only opened when all set conditions hold
 type WindowConfig struct {
-	Name    string `json:"name"`
-	Command string `json:"command"`
+	Name    string `json:"name" yaml:"name" toml:"name"`
+	Command string `json:"command" yaml:"command" toml:"command"`
+
+	IfExists  string `json:"if_exists,omitempty" yaml:"if_exists,omitempty" toml:"if_exists,omitempty"`    // file or glob relative to the repo
+	IfCommand string `json:"if_command,omitempty" yaml:"if_command,omitempty" toml:"if_command,omitempty"` // executable that must be on $PATH
+	IfBranch  string `json:"if_branch,omitempty" yaml:"if_branch,omitempty" toml:"if_branch,omitempty"`    // branch name or glob
+
+	// Per-window environment, applied on top of the session environment
+	EnvFiles []string          `json:"env_files,omitempty" yaml:"env_files,omitempty" toml:"env_files,omitempty"`
+	Env      map[string]string `json:"env,omitempty" yaml:"env,omitempty" toml:"env,omitempty"`
 }
 
 // Config represents the complete configuration
 type Config struct {
-	Version string         `json:"version"`
-	Windows []WindowConfig `json:"windows"`
+	// Schema is the optional "$schema" key editors use for completion; it is kept on save but otherwise ignored
+	Schema string `json:"$schema,omitempty" yaml:"$schema,omitempty" toml:"$schema,omitempty"`
+
+	Version        string             `json:"version" yaml:"version" toml:"version"`
+	Windows        []WindowConfig     `json:"windows" yaml:"windows" toml:"windows"`
+	DefaultProfile string             `json:"default_profile,omitempty" yaml:"default_profile,omitempty" toml:"default_profile,omitempty"`
+	Profiles       map[string]Profile `json:"profiles,omitempty" yaml:"profiles,omitempty" toml:"profiles,omitempty"`
+	Rules          []Rule             `json:"rules,omitempty" yaml:"rules,omitempty" toml:"rules,omitempty"`
+	AutoDetect     *bool              `json:"auto_detect,omitempty" yaml:"auto_detect,omitempty" toml:"auto_detect,omitempty"`
+
+	// RepoConfigStorage selects where repo-level configs live: "git" (default) or "central"
+	RepoConfigStorage string `json:"repo_config_storage,omitempty" yaml:"repo_config_storage,omitempty" toml:"repo_config_storage,omitempty"`
+
+	// Include lists config fragments merged beneath this file (see resolveConfig)
+	Include []string `json:"include,omitempty" yaml:"include,omitempty" toml:"include,omitempty"`
+
+	// UI holds interactive UI preferences (see UIConfig)
+	UI *UIConfig `json:"ui,omitempty" yaml:"ui,omitempty" toml:"ui,omitempty"`
+
+	// Hosts are sections merged in only on matching machines (see applyHosts)
+	Hosts []HostOverride `json:"hosts,omitempty" yaml:"hosts,omitempty" toml:"hosts,omitempty"`
+
+	// Session environment: dotenv files (relative to the repo) are loaded in order, then Env is applied
+	EnvFiles []string          `json:"env_files,omitempty" yaml:"env_files,omitempty" toml:"env_files,omitempty"`
+	Env      map[string]string `json:"env,omitempty" yaml:"env,omitempty" toml:"env,omitempty"`
 }
 
 // GetDefaultConfig returns the default configuration matching current hardcoded behavior
@@ -31,15 +62,73 @@ func GetDefaultConfig() *Config {
 	}
 }
 
+// Validate checks that the config can be used to create a session
+func (c *Config) Validate() error {
+	if len(c.Windows) == 0 {
+		return fmt.Errorf("config must have at least one window")
+	}
+
+	if err := validateWindows(c.Windows); err != nil {
+		return err
+	}
+
+	if err := validateEnv(c.Env); err != nil {
+		return err
+	}
+
+	if err := c.validateProfiles(); err != nil {
+		return err
+	}
+
+	if err := c.validateRepoConfigStorage(); err != nil {
+		return err
+	}
+
+	if err := c.validateUI(); err != nil {
+		return err
+	}
+
+	return c.validateRules()
+}
+
+// validateWindows checks window names and condition patterns
+func validateWindows(windows []WindowConfig) error {
+	for _, window := range windows {
+		if window.Name == "" {
+			return fmt.Errorf("window name cannot be empty")
+		}
+		if _, err := filepath.Match(window.IfExists, ""); err != nil {
+			return fmt.Errorf("window %q: invalid if_exists pattern %q: %w", window.Name, window.IfExists, err)
+		}
+		if _, err := filepath.Match(window.IfBranch, ""); err != nil {
+			return fmt.Errorf("window %q: invalid if_branch pattern %q: %w", window.Name, window.IfBranch, err)
+		}
+		if err := validateEnv(window.Env); err != nil {
+			return fmt.Errorf("window %q: %w", window.Name, err)
+		}
+	}
+	return nil
+}
+
 // GetConfigPath returns the path to the configuration file
+// Precedence: --config-file, $TMUX_SESSIONIZER_CONFIG, then the config directory
+// (see GetConfigDir). Within the directory, the highest-precedence existing format
+// is returned (json, yaml, yml, toml); if none exist, the path of a new config.json is returned
 func GetConfigPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+	explicitPath, err := explicitConfigFile()
+	if err != nil {
+		return "", err
+	}
+	if explicitPath != "" {
+		return explicitPath, nil
+	}
+
+	configDir, err := GetConfigDir()
 	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+		return "", err
 	}
 
-	configDir := filepath.Join(homeDir, ".config", "tmux-sessionizer")
-	configPath := filepath.Join(configDir, "config.json")
+	configPath, _ := findConfigFile(configDir, configFileBase)
 
 	return configPath, nil
 }
@@ -59,69 +148,268 @@ func EnsureConfigDir() error {
 	return nil
 }
 
+// readConfigFile reads, parses and validates a config file in any supported format
+func readConfigFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return parseConfig(path, data)
+}
+
+// parseConfig parses config data in the format implied by path, resolves its
+// include and extends directives, and validates the result
+func parseConfig(path string, data []byte) (*Config, error) {
+	config, err := decodeConfig(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := resolveConfig(path, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resolved.Validate(); err != nil {
+		return nil, err
+	}
+
+	return resolved, nil
+}
+
+// readEditableConfigFile reads a config file for editing
+// The config is returned as written (include and extends unresolved) so saving it
+// doesn't inline included files, but it must still be valid once resolved
+func readEditableConfigFile(path string) (*Config, error) {
+	config, err := readRawConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAt(path, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// validateAt validates a config as it would be resolved if stored at path
+func validateAt(path string, config *Config) error {
+	resolved, err := resolveConfig(path, config)
+	if err != nil {
+		return err
+	}
+	return resolved.Validate()
+}
+
+// writeConfigFile validates and writes a config in the format implied by path
+// If expected is non-nil, the write fails with ErrConfigChanged unless the file
+// still matches it; the check and write happen under the directory lock
+func writeConfigFile(path string, config *Config, expected *Snapshot) error {
+	if config == nil {
+		return fmt.Errorf("config cannot be nil")
+	}
+
+	if err := validateAt(path, config); err != nil {
+		return err
+	}
+
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+
+	data, err := MarshalConfig(config, format)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	return writeConfigData(path, data, expected)
+}
+
+// writeConfigData writes already validated config file contents like writeConfigFile
+func writeConfigData(path string, data []byte, expected *Snapshot) error {
+	unlock, err := lockDir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if expected != nil {
+		current, err := takeSnapshot(path)
+		if err != nil {
+			return err
+		}
+		if !current.matches(*expected) {
+			return ErrConfigChanged
+		}
+	}
+
+	// Keep the version being replaced so it can be restored
+	if err := backupConfigFile(path, data); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data, 0644)
+}
+
+// writeFileAtomic writes data to path via a uniquely named temporary file, fsync and rename
+// An existing file's permissions are preserved; perm is used for new files
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	// Write to temporary file first (atomic write)
+	tempFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	tempPath := tempFile.Name()
+
+	_, err = tempFile.Write(data)
+	if err == nil {
+		err = tempFile.Chmod(perm)
+	}
+	if err == nil {
+		err = tempFile.Sync()
+	}
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// Clean up temp file on error
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	// Rename temp file to actual config file (atomic operation)
+	if err := os.Rename(tempPath, path); err != nil {
+		// Clean up temp file on error
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	// Make the rename itself durable
+	if err := syncDir(dir); err != nil {
+		return fmt.Errorf("failed to sync config directory: %w", err)
+	}
+
+	return nil
+}
+
 // LoadConfig loads configuration from file or returns defaults on any error
 // This implements graceful degradation for backward compatibility
 func LoadConfig() (*Config, error) {
+	config, _ := loadGlobalConfig()
+	return config, nil
+}
+
+// loadGlobalConfig loads the global config, returning the path it was read from
+// The path is empty when defaults were used
+func loadGlobalConfig() (*Config, string) {
 	configPath, err := GetConfigPath()
 	if err != nil {
 		// Can't determine config path, use defaults
-		return GetDefaultConfig(), nil
+		return GetDefaultConfig(), ""
 	}
 
 	// Check if config file exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		// Config file doesn't exist, use defaults
-		return GetDefaultConfig(), nil
+		return GetDefaultConfig(), ""
 	}
 
-	// Read config file
-	data, err := os.ReadFile(configPath)
+	// Read, parse and validate config file
+	config, err := readConfigFile(configPath)
 	if err != nil {
-		// Can't read config file, use defaults
+		// Unreadable or invalid config, use defaults
+		return GetDefaultConfig(), ""
+	}
+
+	return config, configPath
+}
+
+// LoadConfigStrict loads the global config like LoadConfig, but reports unreadable
+// or invalid files instead of falling back to defaults
+// Defaults are still returned when no config file exists
+func LoadConfigStrict() (*Config, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return GetDefaultConfig(), nil
 	}
 
-	// Parse JSON
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		// Invalid JSON, use defaults
+	return readConfigFile(configPath)
+}
+
+// LoadConfigForEdit loads the global config as written, with include and extends
+// directives unresolved, so that saving it preserves them
+// Defaults are returned when no config file exists; invalid files are reported
+func LoadConfigForEdit() (*Config, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return GetDefaultConfig(), nil
 	}
 
-	// Validate config
-	if len(config.Windows) == 0 {
-		// No windows configured, use defaults
+	return readEditableConfigFile(configPath)
+}
+
+// LoadGlobalConfigForRepo loads the global config as written, as the starting point for
+// a new config for repoDir; relative includes are rebased so they still resolve from the
+// repo config's location
+// Defaults are returned when no config file exists; invalid files are reported
+func LoadGlobalConfigForRepo(repoDir string) (*Config, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return GetDefaultConfig(), nil
 	}
 
-	// Validate window names
-	for i, window := range config.Windows {
-		if window.Name == "" {
-			// Invalid window name, use defaults
-			return GetDefaultConfig(), nil
-		}
-		// Sanitize window name to avoid tmux issues
-		config.Windows[i].Name = window.Name
+	config, err := readEditableConfigFile(configPath)
+	if err != nil {
+		return nil, err
 	}
 
-	return &config, nil
+	repoConfigPath, err := GetRepoConfigPath(repoDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := rebaseIncludes(config, configPath, repoConfigPath); err != nil {
+		return nil, err
+	}
+	return config, nil
 }
 
 // SaveConfig saves configuration to file with atomic write
+// The existing file's format is kept; new configs are written as JSON
 func SaveConfig(config *Config) error {
-	if config == nil {
-		return fmt.Errorf("config cannot be nil")
-	}
+	return saveConfig(config, nil)
+}
 
-	// Validate config
-	if len(config.Windows) == 0 {
-		return fmt.Errorf("config must have at least one window")
-	}
+// SaveConfigChecked saves configuration like SaveConfig, but fails with
+// ErrConfigChanged if the file changed since snapshot was taken
+func SaveConfigChecked(config *Config, snapshot Snapshot) error {
+	return saveConfig(config, &snapshot)
+}
 
-	for _, window := range config.Windows {
-		if window.Name == "" {
-			return fmt.Errorf("window name cannot be empty")
-		}
+// saveConfig writes the global config, optionally checking it against a snapshot
+func saveConfig(config *Config, expected *Snapshot) error {
+	if config == nil {
+		return fmt.Errorf("config cannot be nil")
 	}
 
 	// Ensure config directory exists
@@ -134,36 +422,58 @@ func SaveConfig(config *Config) error {
 		return err
 	}
 
-	// Marshal to JSON with indentation
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+	return writeConfigFile(configPath, config, expected)
+}
+
+// GetRepoConfigDir returns the directory holding a repository's local config file
+// In central storage mode this is keyed by the origin remote URL in the global config
+// directory; repositories without an origin, or whose config hasn't been migrated yet,
+// keep using .git/x-tmux-sessionizer
+func GetRepoConfigDir(repoDir string) (string, error) {
+	return repoConfigDir(repoDir, repoConfigStorage())
+}
+
+// repoConfigDir returns the directory holding a repository's local config file under the
+// given storage mode
+func repoConfigDir(repoDir string, storage string) (string, error) {
+	if repoDir == "" {
+		return "", fmt.Errorf("repoDir cannot be empty")
 	}
 
-	// Write to temporary file first (atomic write)
-	tempPath := configPath + ".tmp"
-	if err := os.WriteFile(tempPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
+	gitDir := gitRepoConfigDir(repoDir)
+	if storage != RepoStorageCentral {
+		return gitDir, nil
 	}
 
-	// Rename temp file to actual config file (atomic operation)
-	if err := os.Rename(tempPath, configPath); err != nil {
-		// Clean up temp file on error
-		os.Remove(tempPath)
-		return fmt.Errorf("failed to save config: %w", err)
+	centralDir, ok, err := CentralRepoConfigDir(repoDir)
+	if err != nil || !ok {
+		return gitDir, err
 	}
 
-	return nil
+	// Keep using an existing .git-stored config until it is migrated
+	if _, found := findConfigFile(centralDir, configFileBase); !found {
+		if _, found := findConfigFile(gitDir, configFileBase); found {
+			return gitDir, nil
+		}
+	}
+
+	return centralDir, nil
 }
 
 // GetRepoConfigPath returns the path to a repository's local config file
 // repoDir must be a git repository root directory
 func GetRepoConfigPath(repoDir string) (string, error) {
-	if repoDir == "" {
-		return "", fmt.Errorf("repoDir cannot be empty")
+	return repoConfigPath(repoDir, repoConfigStorage())
+}
+
+// repoConfigPath returns the path to a repository's local config file under the given storage mode
+func repoConfigPath(repoDir string, storage string) (string, error) {
+	configDir, err := repoConfigDir(repoDir, storage)
+	if err != nil {
+		return "", err
 	}
 
-	configPath := filepath.Join(repoDir, ".git", "x-tmux-sessionizer", "config.json")
+	configPath, _ := findConfigFile(configDir, configFileBase)
 	return configPath, nil
 }
 
@@ -185,71 +495,70 @@ func LoadRepoConfig(repoDir string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	return loadRepoConfigFile(configPath)
+}
 
+// loadRepoConfigFile loads the repo config at configPath like LoadRepoConfig
+func loadRepoConfigFile(configPath string) (*Config, error) {
 	// Check if file exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("no repo config found at %s", configPath)
 	}
 
-	// Read config file
-	data, err := os.ReadFile(configPath)
+	// Read, parse and validate config file
+	config, err := readConfigFile(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read repo config: %w", err)
+		return nil, fmt.Errorf("invalid repo config: %w", err)
 	}
 
-	// Parse JSON
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse repo config: %w", err)
+	return config, nil
+}
+
+// LoadRepoConfigForEdit loads a repository's local config as written, with include
+// and extends directives unresolved, so that saving it preserves them
+// Returns error if repo config doesn't exist or can't be read
+func LoadRepoConfigForEdit(repoDir string) (*Config, error) {
+	configPath, err := GetRepoConfigPath(repoDir)
+	if err != nil {
+		return nil, err
 	}
 
-	// Validate config
-	if len(config.Windows) == 0 {
-		return nil, fmt.Errorf("repo config must have at least one window")
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("no repo config found at %s", configPath)
 	}
 
-	// Validate window names
-	for i, window := range config.Windows {
-		if window.Name == "" {
-			return nil, fmt.Errorf("window name cannot be empty")
-		}
-		config.Windows[i].Name = window.Name
+	config, err := readEditableConfigFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repo config: %w", err)
 	}
 
-	return &config, nil
+	return config, nil
 }
 
-// LoadConfigWithFallback loads config with priority: repo-level -> global -> defaults
-// repoDir can be empty string to skip repo config check
+// LoadConfigWithFallback loads config with priority: repo-level -> matching rule ->
+// detected project type -> global -> defaults
+// When a rule or project type decides, the returned config's DefaultProfile is the chosen profile
+// repoDir can be empty string to skip repo config and rule checks
 func LoadConfigWithFallback(repoDir string) (*Config, error) {
-	// Try repo-level config first if repoDir is provided
-	if repoDir != "" {
-		cfg, err := LoadRepoConfig(repoDir)
-		if err == nil {
-			return cfg, nil
-		}
-		// If repo config fails, fall through to global config
-	}
-
-	// Fall back to global config (which has its own fallback to defaults)
-	return LoadConfig()
+	return ResolveConfig(repoDir).Config, nil
 }
 
 // SaveRepoConfig saves configuration to a repository's local config file
+// The existing file's format is kept; new configs are written as JSON
 func SaveRepoConfig(repoDir string, config *Config) error {
-	if config == nil {
-		return fmt.Errorf("config cannot be nil")
-	}
+	return saveRepoConfig(repoDir, config, nil)
+}
 
-	// Validate config
-	if len(config.Windows) == 0 {
-		return fmt.Errorf("config must have at least one window")
-	}
+// SaveRepoConfigChecked saves configuration like SaveRepoConfig, but fails with
+// ErrConfigChanged if the file changed since snapshot was taken
+func SaveRepoConfigChecked(repoDir string, config *Config, snapshot Snapshot) error {
+	return saveRepoConfig(repoDir, config, &snapshot)
+}
 
-	for _, window := range config.Windows {
-		if window.Name == "" {
-			return fmt.Errorf("window name cannot be empty")
-		}
+// saveRepoConfig writes a repo config, optionally checking it against a snapshot
+func saveRepoConfig(repoDir string, config *Config, expected *Snapshot) error {
+	if config == nil {
+		return fmt.Errorf("config cannot be nil")
 	}
 
 	configPath, err := GetRepoConfigPath(repoDir)
@@ -260,27 +569,65 @@ func SaveRepoConfig(repoDir string, config *Config) error {
 	// Create directory structure
 	configDir := filepath.Dir(configPath)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory (check .git permissions): %w", err)
+		return fmt.Errorf("failed to create config directory (check permissions of %s): %w", configDir, err)
 	}
 
-	// Marshal to JSON with indentation
-	data, err := json.MarshalIndent(config, "", "  ")
+	return writeConfigFile(configPath, config, expected)
+}
+
+// CopyRepoConfig copies the repo-level config of fromRepo to toRepo, replacing any existing one
+// The file is copied as written, so include, extends and host sections are kept. It keeps
+// its format unless toRepo already has a config in another one, and relative includes that
+// would name other files from the new location are made absolute.
+func CopyRepoConfig(fromRepo string, toRepo string) error {
+	fromPath, err := GetRepoConfigPath(fromRepo)
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+		return err
+	}
+	if _, err := os.Stat(fromPath); os.IsNotExist(err) {
+		return fmt.Errorf("no repo config found at %s", fromPath)
 	}
 
-	// Write to temporary file first (atomic write)
-	tempPath := configPath + ".tmp"
-	if err := os.WriteFile(tempPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
+	data, err := os.ReadFile(fromPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	cfg, err := decodeConfig(fromPath, data)
+	if err != nil {
+		return err
 	}
 
-	// Rename temp file to actual config file (atomic operation)
-	if err := os.Rename(tempPath, configPath); err != nil {
-		// Clean up temp file on error
-		os.Remove(tempPath)
-		return fmt.Errorf("failed to save config: %w", err)
+	// Replace an existing config in place; otherwise use the source's format
+	toPath, err := GetRepoConfigPath(toRepo)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(toPath); os.IsNotExist(err) {
+		toPath = filepath.Join(filepath.Dir(toPath), configFileBase+filepath.Ext(fromPath))
 	}
 
-	return nil
+	rebased, err := rebaseIncludes(cfg, fromPath, toPath)
+	if err != nil {
+		return err
+	}
+	if err := validateAt(toPath, cfg); err != nil {
+		return fmt.Errorf("invalid repo config: %w", err)
+	}
+
+	// Re-encode only when the copy can't be byte-for-byte
+	if rebased || filepath.Ext(toPath) != filepath.Ext(fromPath) {
+		format, err := FormatFromPath(toPath)
+		if err != nil {
+			return err
+		}
+		if data, err = MarshalConfig(cfg, format); err != nil {
+			return fmt.Errorf("failed to marshal config: %w", err)
+		}
+	}
+
+	configDir := filepath.Dir(toPath)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory (check permissions of %s): %w", configDir, err)
+	}
+	return writeConfigData(toPath, data, nil)
 }
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// projectMarker maps a file found in a repository root to a project type
+type projectMarker struct {
+	File string
+	Type string
+}
+
+// projectMarkers are checked in order; the first file present decides the project type
+var projectMarkers = []projectMarker{
+	{"go.mod", "go"},
+	{"Cargo.toml", "rust"},
+	{"package.json", "node"},
+	{"pyproject.toml", "python"},
+	{"docker-compose.yml", "docker"},
+	{"docker-compose.yaml", "docker"},
+	{"compose.yml", "docker"},
+	{"compose.yaml", "docker"},
+	{"Makefile", "make"},
+}
+
+// builtinTemplates are the layouts used for detected project types when the
+// user hasn't defined a profile of the same name
+var builtinTemplates = map[string][]WindowConfig{
+	"go": {
+		{Name: "nvim", Command: "nvim"},
+		{Name: "test", Command: "go test ./..."},
+		{Name: "term", Command: ""},
+	},
+	"rust": {
+		{Name: "nvim", Command: "nvim"},
+		{Name: "cargo", Command: "cargo check"},
+		{Name: "term", Command: ""},
+	},
+	"node": {
+		{Name: "nvim", Command: "nvim"},
+		{Name: "server", Command: "npm run dev"},
+		{Name: "term", Command: ""},
+	},
+	"python": {
+		{Name: "nvim", Command: "nvim"},
+		{Name: "test", Command: "python -m pytest"},
+		{Name: "term", Command: ""},
+	},
+	"docker": {
+		{Name: "nvim", Command: "nvim"},
+		{Name: "docker", Command: "docker compose up"},
+		{Name: "term", Command: ""},
+	},
+	"make": {
+		{Name: "nvim", Command: "nvim"},
+		{Name: "make", Command: "make"},
+		{Name: "term", Command: ""},
+	},
+}
+
+// DetectProjectType inspects repoDir for marker files and returns its project type
+// Returns an empty string if no marker is found
+func DetectProjectType(repoDir string) string {
+	for _, marker := range projectMarkers {
+		if _, err := os.Stat(filepath.Join(repoDir, marker.File)); err == nil {
+			return marker.Type
+		}
+	}
+	return ""
+}
+
+// BuiltinTemplate returns a copy of the built-in layout for a project type
+func BuiltinTemplate(projectType string) ([]WindowConfig, bool) {
+	windows, ok := builtinTemplates[projectType]
+	if !ok {
+		return nil, false
+	}
+	return append([]WindowConfig(nil), windows...), true
+}
+
+// AutoDetectEnabled reports whether project type detection is enabled (the default)
+func (c *Config) AutoDetectEnabled() bool {
+	return c.AutoDetect == nil || *c.AutoDetect
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ParseDotenv parses the contents of a .env file
+// Supported syntax:
+//
+//	# comment
+//	KEY=value              unquoted; surrounding whitespace and a trailing " # comment" are removed
+//	export KEY=value       the export prefix is ignored
+//	KEY='literal value'    no escapes are processed; may span lines
+//	KEY="escaped\tvalue"   \n, \r, \t, \", \\ and \$ escapes are processed; may span lines
+//
+// Values are taken literally; no variable expansion is performed.
+func ParseDotenv(data []byte) (map[string]string, error) {
+	env := make(map[string]string)
+	s := strings.ReplaceAll(string(data), "\r\n", "\n")
+	lineNum := 0
+
+	for len(s) > 0 {
+		var line string
+		line, s = cutLine(s)
+		lineNum++
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		trimmed = strings.TrimPrefix(trimmed, "export ")
+
+		key, rest, ok := strings.Cut(trimmed, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !isValidEnvName(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNum)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+
+		if rest == "" || (rest[0] != '\'' && rest[0] != '"') {
+			// Unquoted value ends at an inline comment
+			if idx := strings.Index(rest, " #"); idx != -1 {
+				rest = rest[:idx]
+			}
+			if idx := strings.Index(rest, "\t#"); idx != -1 {
+				rest = rest[:idx]
+			}
+			env[key] = strings.TrimSpace(rest)
+			continue
+		}
+
+		// Quoted values may continue onto following lines
+		quote := rest[0]
+		body := rest[1:] + "\n" + s
+		value, remaining, consumedLines, err := parseQuoted(body, quote)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		lineNum += consumedLines
+
+		// Only whitespace or a comment may follow the closing quote
+		tail, next := cutLine(remaining)
+		tail = strings.TrimSpace(tail)
+		if tail != "" && !strings.HasPrefix(tail, "#") {
+			return nil, fmt.Errorf("line %d: unexpected %q after quoted value", lineNum, tail)
+		}
+		s = next
+		env[key] = value
+	}
+
+	return env, nil
+}
+
+// cutLine splits s at the first newline
+func cutLine(s string) (line string, rest string) {
+	line, rest, _ = strings.Cut(s, "\n")
+	return line, rest
+}
+
+// parseQuoted reads a quoted value up to its closing quote
+// It returns the value, the input following the closing quote, and how many newlines were consumed
+func parseQuoted(s string, quote byte) (string, string, int, error) {
+	var value strings.Builder
+	lines := 0
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			return value.String(), s[i+1:], lines, nil
+		case c == '\\' && quote == '"' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case '"', '\\', '$':
+				value.WriteByte(s[i])
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(s[i])
+			}
+		default:
+			if c == '\n' {
+				lines++
+			}
+			value.WriteByte(c)
+		}
+	}
+
+	return "", "", lines, fmt.Errorf("unterminated %c-quoted value", quote)
+}
+
+// isValidEnvName reports whether name is a valid environment variable name
+func isValidEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// LoadEnvFiles reads dotenv files in order, later files overriding earlier ones
+// Relative paths are resolved against dir; files that don't exist are skipped
+func LoadEnvFiles(dir string, files []string) (map[string]string, error) {
+	env := make(map[string]string)
+
+	for _, file := range files {
+		path := expandHome(file)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read env file: %w", err)
+		}
+
+		fileEnv, err := ParseDotenv(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse env file %s: %w", path, err)
+		}
+		for key, value := range fileEnv {
+			env[key] = value
+		}
+	}
+
+	return env, nil
+}
+
+// validateEnv checks variable names in an env map
+func validateEnv(env map[string]string) error {
+	for key := range env {
+		if !isValidEnvName(key) {
+			return fmt.Errorf("invalid environment variable name %q", key)
+		}
+	}
+	return nil
+}
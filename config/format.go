@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format identifies the encoding of a config file
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// configFileBase is the file name (without extension) of every config file
+const configFileBase = "config"
+
+// formatExtensions lists the recognized file extensions in precedence order.
+// When several config files exist side by side, the first one found wins.
+var formatExtensions = []struct {
+	Ext    string
+	Format Format
+}{
+	{".json", FormatJSON},
+	{".yaml", FormatYAML},
+	{".yml", FormatYAML},
+	{".toml", FormatTOML},
+}
+
+// ParseFormat converts a user-supplied format name into a Format
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "toml":
+		return FormatTOML, nil
+	}
+	return "", fmt.Errorf("unsupported config format %q (expected json, yaml or toml)", name)
+}
+
+// FormatFromPath determines the config format from a file's extension
+func FormatFromPath(path string) (Format, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, candidate := range formatExtensions {
+		if candidate.Ext == ext {
+			return candidate.Format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported config file extension %q", ext)
+}
+
+// Extension returns the file extension used when writing this format
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// findConfigFile returns the highest-precedence config file named base.<ext> in dir.
+// If none exists, the JSON path is returned with found set to false.
+func findConfigFile(dir string, base string) (path string, found bool) {
+	for _, candidate := range formatExtensions {
+		path := filepath.Join(dir, base+candidate.Ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return filepath.Join(dir, base+FormatJSON.Extension()), false
+}
+
+// MarshalConfig encodes a config in the given format
+func MarshalConfig(config *Config, format Format) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(config, "", "  ")
+	case FormatYAML:
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(config); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case FormatTOML:
+		var buf bytes.Buffer
+		encoder := toml.NewEncoder(&buf)
+		encoder.Indent = ""
+		if err := encoder.Encode(config); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported config format %q", format)
+}
+
+// UnmarshalConfig decodes a config in the given format
+func UnmarshalConfig(data []byte, format Format, config *Config) error {
+	switch format {
+	case FormatJSON:
+		return json.Unmarshal(data, config)
+	case FormatYAML:
+		return yaml.Unmarshal(data, config)
+	case FormatTOML:
+		return toml.Unmarshal(data, config)
+	}
+	return fmt.Errorf("unsupported config format %q", format)
+}
+
+// ConvertConfigFile rewrites the config file at path in another format and
+// removes the original. It returns the path of the new file.
+func ConvertConfigFile(path string, to Format) (string, error) {
+	from, err := FormatFromPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	config, err := readEditableConfigFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	newPath := strings.TrimSuffix(path, filepath.Ext(path)) + to.Extension()
+	if from == to && newPath == path {
+		return path, nil
+	}
+
+	if err := writeConfigFile(newPath, config, nil); err != nil {
+		return "", err
+	}
+
+	// Remove the original so it doesn't shadow the new file
+	if err := os.Remove(path); err != nil {
+		return "", fmt.Errorf("converted config written to %s but failed to remove %s: %w", newPath, path, err)
+	}
+
+	return newPath, nil
+}
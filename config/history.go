@@ -0,0 +1,194 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxHistoryEntries is the number of previous versions kept per config file
+const maxHistoryEntries = 20
+
+// HistoryEntry is a saved previous version of a config file
+type HistoryEntry struct {
+	Index int       // 1 is the most recent previous version
+	Time  time.Time // when the version was replaced
+	Path  string    // location of the saved copy
+}
+
+// historyDir returns the directory holding previous versions of the config file at path
+// Each config file gets its own directory, keyed by a hash of its absolute path
+func historyDir(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve config path: %w", err)
+	}
+
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(absPath))
+	return filepath.Join(stateDir, "history", hex.EncodeToString(sum[:8])), nil
+}
+
+// backupConfigFile saves the current content of path to its history before it is
+// replaced with newData; nothing is saved if the file doesn't exist or is unchanged
+func backupConfigFile(path string, newData []byte) error {
+	current, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config for backup: %w", err)
+	}
+	if bytes.Equal(current, newData) {
+		return nil
+	}
+
+	dir, err := historyDir(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	// Record which file this history belongs to
+	if err := os.WriteFile(filepath.Join(dir, "path"), []byte(path), 0600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	name := strconv.FormatInt(time.Now().UnixNano(), 10) + filepath.Ext(path)
+	if err := os.WriteFile(filepath.Join(dir, name), current, 0600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	return pruneHistory(path)
+}
+
+// pruneHistory removes all but the newest maxHistoryEntries versions
+func pruneHistory(path string) error {
+	entries, err := ConfigHistory(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Index > maxHistoryEntries {
+			os.Remove(entry.Path)
+		}
+	}
+	return nil
+}
+
+// ConfigHistory lists the saved previous versions of the config file at path, newest first
+func ConfigHistory(path string) ([]HistoryEntry, error) {
+	dir, err := historyDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var entries []HistoryEntry
+	for _, file := range files {
+		stamp, ext, _ := strings.Cut(file.Name(), ".")
+		nanos, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil || "."+ext != filepath.Ext(path) {
+			continue
+		}
+		entries = append(entries, HistoryEntry{
+			Time: time.Unix(0, nanos),
+			Path: filepath.Join(dir, file.Name()),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+	for i := range entries {
+		entries[i].Index = i + 1
+	}
+
+	return entries, nil
+}
+
+// historyEntry returns the n-th most recent previous version of path (1-based)
+func historyEntry(path string, n int) (*HistoryEntry, error) {
+	entries, err := ConfigHistory(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no history for %s", path)
+	}
+	if n < 1 || n > len(entries) {
+		return nil, fmt.Errorf("version %d out of range (1-%d)", n, len(entries))
+	}
+	return &entries[n-1], nil
+}
+
+// readConfigVersion reads the n-th most recent previous version of path and checks
+// that it is still valid where it would be restored
+func readConfigVersion(path string, n int) ([]byte, *Config, error) {
+	entry, err := historyEntry(path, n)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := os.ReadFile(entry.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	config, err := decodeConfig(path, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateAt(path, config); err != nil {
+		return nil, nil, fmt.Errorf("version %d is not valid anymore: %w", n, err)
+	}
+	return data, config, nil
+}
+
+// LoadConfigVersion loads the n-th most recent previous version of path for editing
+func LoadConfigVersion(path string, n int) (*Config, error) {
+	_, config, err := readConfigVersion(path, n)
+	return config, err
+}
+
+// RestoreConfigVersion replaces the config file at path with its n-th most recent
+// previous version; the current content is added to the history first, so a
+// restore can itself be undone
+func RestoreConfigVersion(path string, n int) error {
+	data, _, err := readConfigVersion(path, n)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockDir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// The exact bytes are restored so comments and formatting survive
+	if err := backupConfigFile(path, data); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// HostEnv overrides the hostname host sections are matched against
+const HostEnv = "TMUX_SESSIONIZER_HOST"
+
+// HostOverride is a config section that only applies on matching machines
+// When both conditions are set, both must hold.
+type HostOverride struct {
+	IfHost string `json:"if_host,omitempty" yaml:"if_host,omitempty" toml:"if_host,omitempty"` // hostname or glob
+	IfEnv  string `json:"if_env,omitempty" yaml:"if_env,omitempty" toml:"if_env,omitempty"`    // VAR (set and non-empty) or VAR=value
+
+	// Windows replace top-level windows of the same name and are otherwise appended
+	Windows []WindowConfig `json:"windows,omitempty" yaml:"windows,omitempty" toml:"windows,omitempty"`
+
+	// The remaining fields are merged like an included file
+	DefaultProfile string             `json:"default_profile,omitempty" yaml:"default_profile,omitempty" toml:"default_profile,omitempty"`
+	Profiles       map[string]Profile `json:"profiles,omitempty" yaml:"profiles,omitempty" toml:"profiles,omitempty"`
+	Rules          []Rule             `json:"rules,omitempty" yaml:"rules,omitempty" toml:"rules,omitempty"`
+	EnvFiles       []string           `json:"env_files,omitempty" yaml:"env_files,omitempty" toml:"env_files,omitempty"`
+	Env            map[string]string  `json:"env,omitempty" yaml:"env,omitempty" toml:"env,omitempty"`
+}
+
+// Hostname returns the name host sections are matched against: $TMUX_SESSIONIZER_HOST
+// if set, otherwise the system hostname
+func Hostname() (string, error) {
+	if name := os.Getenv(HostEnv); name != "" {
+		return name, nil
+	}
+	name, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("failed to get hostname: %w", err)
+	}
+	return name, nil
+}
+
+// Matches reports whether the override applies on a machine called hostname
+// Host patterns match either the full hostname or its first label (e.g. "laptop" for "laptop.local").
+func (h HostOverride) Matches(hostname string) bool {
+	if h.IfHost != "" {
+		short, _, _ := strings.Cut(hostname, ".")
+		full, _ := path.Match(h.IfHost, hostname)
+		label, _ := path.Match(h.IfHost, short)
+		if !full && !label {
+			return false
+		}
+	}
+
+	if h.IfEnv != "" {
+		name, want, hasValue := strings.Cut(h.IfEnv, "=")
+		value := os.Getenv(name)
+		if hasValue && value != want {
+			return false
+		}
+		if !hasValue && value == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String describes the override's conditions
+func (h HostOverride) String() string {
+	var conditions []string
+	if h.IfHost != "" {
+		conditions = append(conditions, "host "+h.IfHost)
+	}
+	if h.IfEnv != "" {
+		conditions = append(conditions, "env "+h.IfEnv)
+	}
+	return strings.Join(conditions, " and ")
+}
+
+// validateHosts checks every host section, including ones that don't match this machine
+func (c *Config) validateHosts() error {
+	for i, host := range c.Hosts {
+		if host.IfHost == "" && host.IfEnv == "" {
+			return fmt.Errorf("hosts[%d]: if_host or if_env is required", i)
+		}
+		if _, err := path.Match(host.IfHost, ""); err != nil {
+			return fmt.Errorf("hosts[%d]: invalid if_host pattern %q: %w", i, host.IfHost, err)
+		}
+		if name, _, _ := strings.Cut(host.IfEnv, "="); host.IfEnv != "" && !isValidEnvName(name) {
+			return fmt.Errorf("hosts[%d]: invalid if_env variable name %q", i, name)
+		}
+		if len(host.Windows) > 0 {
+			if err := validateWindows(host.Windows); err != nil {
+				return fmt.Errorf("hosts[%d]: %w", i, err)
+			}
+		}
+		if err := validateEnv(host.Env); err != nil {
+			return fmt.Errorf("hosts[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// applyHosts merges the host sections matching this machine, in order, then drops them
+func (c *Config) applyHosts() error {
+	if len(c.Hosts) == 0 {
+		return nil
+	}
+
+	if err := c.validateHosts(); err != nil {
+		return err
+	}
+
+	hostname, err := Hostname()
+	if err != nil {
+		return err
+	}
+
+	for _, host := range c.Hosts {
+		if !host.Matches(hostname) {
+			continue
+		}
+		c.Windows = overlayWindows(c.Windows, host.Windows)
+		c.merge(&Config{
+			DefaultProfile: host.DefaultProfile,
+			Profiles:       host.Profiles,
+			Rules:          host.Rules,
+			EnvFiles:       host.EnvFiles,
+			Env:            host.Env,
+		})
+	}
+
+	c.Hosts = nil
+	return nil
+}
@@ -0,0 +1,330 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// resolveConfig returns a copy of a config read from path with its include and
+// extends directives applied
+// Included files are merged in order, then the including file is layered on top,
+// then host sections matching this machine.
+// Relative include paths are resolved against the including file's directory.
+func resolveConfig(path string, config *Config) (*Config, error) {
+	resolved, err := resolveIncludes(path, config, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resolved.applyHosts(); err != nil {
+		return nil, err
+	}
+
+	if err := resolved.resolveExtends(); err != nil {
+		return nil, err
+	}
+
+	return resolved, nil
+}
+
+// resolveIncludes merges a config's includes beneath it; stack holds the files being
+// resolved so that include cycles are reported instead of recursing forever
+func resolveIncludes(path string, config *Config, stack []string) (*Config, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	for _, seen := range stack {
+		if seen == absPath {
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(append(stack, absPath), " -> "))
+		}
+	}
+	stack = append(stack, absPath)
+
+	merged := &Config{}
+	for _, include := range config.Include {
+		includePath := includeTarget(absPath, include)
+		fragment, err := readRawConfig(includePath)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", include, err)
+		}
+
+		resolvedFragment, err := resolveIncludes(includePath, fragment, stack)
+		if err != nil {
+			return nil, err
+		}
+		merged.merge(resolvedFragment)
+	}
+
+	merged.merge(config)
+	merged.Include = nil
+	return merged, nil
+}
+
+// includeTarget returns the file an include of the config at absPath refers to
+func includeTarget(absPath string, include string) string {
+	includePath := expandHome(include)
+	if !filepath.IsAbs(includePath) {
+		includePath = filepath.Join(filepath.Dir(absPath), includePath)
+	}
+	return includePath
+}
+
+// InheritIncludedWindows copies the windows that a config stored at path inherits from its
+// includes into the config itself, so that editing the list keeps them: a config's own
+// windows replace included ones instead of adding to them. profile selects a profile's
+// windows; "" or "default" is the top-level list. Nothing is copied if the config already
+// sets the list. Returns the include the windows came from, or "" if none were copied.
+func (c *Config) InheritIncludedWindows(path string, profile string) (string, error) {
+	layout, err := IncludedLayoutOf(path, c)
+	if err != nil {
+		return "", err
+	}
+
+	if profile == "" || profile == DefaultProfileName {
+		if len(c.Windows) > 0 || len(layout.Windows) == 0 {
+			return "", nil
+		}
+		c.Windows = append([]WindowConfig(nil), layout.Windows...)
+		return layout.WindowsFrom, nil
+	}
+
+	if _, ok := c.Profiles[profile]; ok {
+		return "", nil
+	}
+	included, ok := layout.Profiles[profile]
+	if !ok {
+		return "", nil
+	}
+	included.Windows = append([]WindowConfig(nil), included.Windows...)
+	profiles := make(map[string]Profile, len(c.Profiles)+1)
+	for name, existing := range c.Profiles {
+		profiles[name] = existing
+	}
+	profiles[profile] = included
+	c.Profiles = profiles
+	return layout.ProfilesFrom[profile], nil
+}
+
+// IncludedLayout holds the windows and profiles a config inherits from its includes
+type IncludedLayout struct {
+	Windows      []WindowConfig     // top-level windows, if an include sets them
+	WindowsFrom  string             // the include the top-level windows come from
+	Profiles     map[string]Profile // profiles defined by includes
+	ProfilesFrom map[string]string  // the include each profile comes from
+}
+
+// IncludedLayoutOf returns what a config stored at path inherits from its includes (without
+// its own settings), remembering which include each window list and profile comes from
+func IncludedLayoutOf(path string, config *Config) (*IncludedLayout, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config path: %w", err)
+	}
+
+	layout := &IncludedLayout{Profiles: make(map[string]Profile), ProfilesFrom: make(map[string]string)}
+	for _, include := range config.Include {
+		includePath := includeTarget(absPath, include)
+		fragment, err := readRawConfig(includePath)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", include, err)
+		}
+
+		resolved, err := resolveIncludes(includePath, fragment, []string{absPath})
+		if err != nil {
+			return nil, err
+		}
+
+		// Later includes win, as when merging
+		if len(resolved.Windows) > 0 {
+			layout.Windows = resolved.Windows
+			layout.WindowsFrom = include
+		}
+		for name, profile := range resolved.Profiles {
+			layout.Profiles[name] = profile
+			layout.ProfilesFrom[name] = include
+		}
+	}
+	return layout, nil
+}
+
+// rebaseIncludes rewrites the relative includes of a config written for fromPath so that
+// they still name the same files when it is stored at toPath; includes that would point
+// elsewhere are made absolute. Reports whether any include was rewritten.
+func rebaseIncludes(config *Config, fromPath string, toPath string) (bool, error) {
+	fromDir, err := filepath.Abs(filepath.Dir(fromPath))
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	toDir, err := filepath.Abs(filepath.Dir(toPath))
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve config path: %w", err)
+	}
+
+	rebased := false
+	for i, include := range config.Include {
+		includePath := expandHome(include)
+		if filepath.IsAbs(includePath) {
+			continue
+		}
+		if target := filepath.Join(fromDir, includePath); target != filepath.Join(toDir, includePath) {
+			config.Include[i] = target
+			rebased = true
+		}
+	}
+	return rebased, nil
+}
+
+// readRawConfig reads and decodes a config file without resolving or validating it
+func readRawConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return decodeConfig(path, data)
+}
+
+// decodeConfig decodes config data in the format implied by path
+func decodeConfig(path string, data []byte) (*Config, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := UnmarshalConfig(data, format, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// merge layers other on top of c
+// Scalars and the top-level window list are replaced when set in other, profiles
+// and env values are replaced by name, and rules, env files and host sections are appended
+func (c *Config) merge(other *Config) {
+	if other.Schema != "" {
+		c.Schema = other.Schema
+	}
+	if other.Version != "" {
+		c.Version = other.Version
+	}
+	if len(other.Windows) > 0 {
+		c.Windows = append([]WindowConfig(nil), other.Windows...)
+	}
+	if other.DefaultProfile != "" {
+		c.DefaultProfile = other.DefaultProfile
+	}
+	if len(other.Profiles) > 0 {
+		profiles := make(map[string]Profile, len(c.Profiles)+len(other.Profiles))
+		for name, profile := range c.Profiles {
+			profiles[name] = profile
+		}
+		for name, profile := range other.Profiles {
+			profiles[name] = profile
+		}
+		c.Profiles = profiles
+	}
+	c.Rules = append(append([]Rule(nil), c.Rules...), other.Rules...)
+	if other.AutoDetect != nil {
+		c.AutoDetect = other.AutoDetect
+	}
+	if other.RepoConfigStorage != "" {
+		c.RepoConfigStorage = other.RepoConfigStorage
+	}
+	c.EnvFiles = append(append([]string(nil), c.EnvFiles...), other.EnvFiles...)
+	if len(other.Env) > 0 {
+		env := make(map[string]string, len(c.Env)+len(other.Env))
+		for key, value := range c.Env {
+			env[key] = value
+		}
+		for key, value := range other.Env {
+			env[key] = value
+		}
+		c.Env = env
+	}
+	if other.UI != nil {
+		ui := &UIConfig{}
+		if c.UI != nil {
+			*ui = *c.UI
+		}
+		ui.merge(other.UI)
+		c.UI = ui
+	}
+	c.Include = append(append([]string(nil), c.Include...), other.Include...)
+	c.Hosts = append(append([]HostOverride(nil), c.Hosts...), other.Hosts...)
+}
+
+// resolveExtends replaces every profile's windows with those inherited through extends
+// A profile's own windows replace inherited windows of the same name and are otherwise appended
+func (c *Config) resolveExtends() error {
+	resolved := make(map[string][]WindowConfig, len(c.Profiles))
+
+	var resolve func(name string, chain []string) ([]WindowConfig, error)
+	resolve = func(name string, chain []string) ([]WindowConfig, error) {
+		if name == DefaultProfileName {
+			return c.Windows, nil
+		}
+		if windows, ok := resolved[name]; ok {
+			return windows, nil
+		}
+		for _, seen := range chain {
+			if seen == name {
+				return nil, fmt.Errorf("profile extends cycle: %s", strings.Join(append(chain, name), " -> "))
+			}
+		}
+
+		profile, ok := c.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("profile %q extends undefined profile %q", chain[len(chain)-1], name)
+		}
+		if profile.Extends == "" {
+			resolved[name] = profile.Windows
+			return profile.Windows, nil
+		}
+
+		parent, err := resolve(profile.Extends, append(chain, name))
+		if err != nil {
+			return nil, err
+		}
+		windows := overlayWindows(parent, profile.Windows)
+		resolved[name] = windows
+		return windows, nil
+	}
+
+	profiles := make(map[string]Profile, len(c.Profiles))
+	for name := range c.Profiles {
+		windows, err := resolve(name, nil)
+		if err != nil {
+			return err
+		}
+		profiles[name] = Profile{Windows: windows}
+	}
+	if c.Profiles != nil {
+		c.Profiles = profiles
+	}
+
+	return nil
+}
+
+// overlayWindows applies child windows on top of a copy of the parent's windows
+func overlayWindows(parent []WindowConfig, child []WindowConfig) []WindowConfig {
+	windows := append([]WindowConfig(nil), parent...)
+	for _, window := range child {
+		replaced := false
+		for i := range windows {
+			if windows[i].Name == window.Name {
+				windows[i] = window
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			windows = append(windows, window)
+		}
+	}
+	return windows
+}
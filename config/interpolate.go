@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Variables holds the values available to ${...} references in window commands
+type Variables map[string]string
+
+// builtinVariables are the names ExpandVariables resolves itself; other ${...}
+// references are left for the shell, which expands them when the command runs
+var builtinVariables = map[string]bool{
+	"repo":    true,
+	"path":    true,
+	"session": true,
+	"branch":  true,
+}
+
+// ExpandVariables replaces ${name} references in s
+// Supported forms:
+//
+//	${name}            a variable from vars (repo, path, session, branch)
+//	${env:NAME}        an environment variable
+//	${name:-fallback}  fallback is used when the variable is undefined or empty
+//	$${                a literal "${"
+//
+// References to other names, such as ${HOME}, are kept as written for the shell.
+// Referencing an undefined built-in variable without a fallback is an error.
+func ExpandVariables(s string, vars Variables) (string, error) {
+	var out strings.Builder
+
+	for {
+		start := strings.Index(s, "${")
+		if start == -1 {
+			out.WriteString(s)
+			return out.String(), nil
+		}
+
+		// $${ escapes a literal ${
+		if start > 0 && s[start-1] == '$' {
+			out.WriteString(s[:start-1])
+			out.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+
+		end := strings.IndexByte(s[start:], '}')
+		if end == -1 {
+			return "", fmt.Errorf("unterminated variable reference %q", s[start:])
+		}
+		end += start
+
+		value, ok, err := lookupVariable(s[start+2:end], vars)
+		if err != nil {
+			return "", err
+		}
+
+		if ok {
+			out.WriteString(s[:start])
+			out.WriteString(value)
+		} else {
+			out.WriteString(s[:end+1])
+		}
+		s = s[end+1:]
+	}
+}
+
+// lookupVariable resolves the body of a ${...} reference
+// ok is false for references that aren't built-in or env: variables, which are left to the shell.
+func lookupVariable(ref string, vars Variables) (value string, ok bool, err error) {
+	name, fallback, hasFallback := strings.Cut(ref, ":-")
+	if name == "" {
+		return "", false, fmt.Errorf("empty variable reference ${%s}", ref)
+	}
+
+	var defined bool
+	if envName, isEnv := strings.CutPrefix(name, "env:"); isEnv {
+		value, defined = os.LookupEnv(envName)
+	} else if builtinVariables[name] {
+		value, defined = vars[name]
+	} else {
+		return "", false, nil
+	}
+
+	if hasFallback && value == "" {
+		return fallback, true, nil
+	}
+	if !defined {
+		return "", false, fmt.Errorf("undefined variable ${%s}", name)
+	}
+	return value, true, nil
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package config
+
+// lockDir is a no-op where advisory directory locks aren't available
+func lockDir(dir string) (func(), error) {
+	return func() {}, nil
+}
+
+// syncDir is a no-op where directories can't be synced
+func syncDir(dir string) error {
+	return nil
+}
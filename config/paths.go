@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// appName is the directory name used under the XDG base directories
+const appName = "tmux-sessionizer"
+
+// ConfigFileEnv names the environment variable that overrides the global config file path
+const ConfigFileEnv = "TMUX_SESSIONIZER_CONFIG"
+
+// configFileOverride is the config file path set with SetConfigFile (e.g. from --config-file)
+var configFileOverride string
+
+// SetConfigFile overrides the global config file location
+// It takes precedence over $TMUX_SESSIONIZER_CONFIG and the XDG config directory
+func SetConfigFile(path string) error {
+	if path == "" {
+		configFileOverride = ""
+		return nil
+	}
+
+	absPath, err := filepath.Abs(expandHome(path))
+	if err != nil {
+		return fmt.Errorf("failed to resolve config file path: %w", err)
+	}
+
+	if _, err := FormatFromPath(absPath); err != nil {
+		return err
+	}
+
+	configFileOverride = absPath
+	return nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(homeDir, path[1:])
+}
+
+// xdgDir returns $envVar/tmux-sessionizer, falling back to ~/fallback/tmux-sessionizer
+// Relative values are ignored, as required by the XDG base directory specification
+func xdgDir(envVar string, fallback string) (string, error) {
+	if dir := os.Getenv(envVar); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	return filepath.Join(homeDir, fallback, appName), nil
+}
+
+// explicitConfigFile returns the config file chosen by --config-file or $TMUX_SESSIONIZER_CONFIG
+func explicitConfigFile() (string, error) {
+	if configFileOverride != "" {
+		return configFileOverride, nil
+	}
+
+	if path := os.Getenv(ConfigFileEnv); path != "" {
+		absPath, err := filepath.Abs(expandHome(path))
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", ConfigFileEnv, err)
+		}
+		if _, err := FormatFromPath(absPath); err != nil {
+			return "", fmt.Errorf("invalid %s: %w", ConfigFileEnv, err)
+		}
+		return absPath, nil
+	}
+
+	return "", nil
+}
+
+// HasExplicitConfigFile reports whether the global config file was chosen with
+// --config-file or $TMUX_SESSIONIZER_CONFIG rather than found in the config directory
+func HasExplicitConfigFile() bool {
+	path, err := explicitConfigFile()
+	return err == nil && path != ""
+}
+
+// GetConfigDir returns the directory holding the global config file
+// This is the directory of an explicit config file if one was given, otherwise
+// $XDG_CONFIG_HOME/tmux-sessionizer (default ~/.config/tmux-sessionizer)
+func GetConfigDir() (string, error) {
+	path, err := explicitConfigFile()
+	if err != nil {
+		return "", err
+	}
+	if path != "" {
+		return filepath.Dir(path), nil
+	}
+
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// GetStateDir returns the directory for persistent state such as trust and history data
+// $XDG_STATE_HOME/tmux-sessionizer (default ~/.local/state/tmux-sessionizer)
+func GetStateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
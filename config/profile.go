@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultProfileName refers to the top-level window list of a config
+const DefaultProfileName = "default"
+
+// Profile is a named window layout that can be chosen at session creation
+// Extends names another profile (or "default") whose windows this profile builds on
+type Profile struct {
+	Extends string         `json:"extends,omitempty" yaml:"extends,omitempty" toml:"extends,omitempty"`
+	Windows []WindowConfig `json:"windows" yaml:"windows" toml:"windows"`
+}
+
+// validateProfiles checks profile names, windows and the default profile reference
+func (c *Config) validateProfiles() error {
+	for name, profile := range c.Profiles {
+		if name == "" {
+			return fmt.Errorf("profile name cannot be empty")
+		}
+		if name == DefaultProfileName {
+			return fmt.Errorf("profile name %q is reserved for the top-level windows", DefaultProfileName)
+		}
+		if len(profile.Windows) == 0 {
+			return fmt.Errorf("profile %q must have at least one window", name)
+		}
+		if err := validateWindows(profile.Windows); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
+
+	if c.DefaultProfile != "" && c.DefaultProfile != DefaultProfileName {
+		if _, ok := c.Profiles[c.DefaultProfile]; !ok {
+			return fmt.Errorf("default_profile %q is not defined", c.DefaultProfile)
+		}
+	}
+
+	return nil
+}
+
+// ProfileNames returns every selectable profile, default profile first
+// The top-level windows are listed as "default"; the rest are sorted by name
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles)+1)
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	names = append([]string{DefaultProfileName}, names...)
+
+	// Move the configured default profile to the front
+	defaultName := c.DefaultProfileOrDefault()
+	for i, name := range names {
+		if name == defaultName {
+			copy(names[1:i+1], names[:i])
+			names[0] = defaultName
+			break
+		}
+	}
+
+	return names
+}
+
+// DefaultProfileOrDefault returns the configured default profile name, or "default"
+func (c *Config) DefaultProfileOrDefault() string {
+	if c.DefaultProfile == "" {
+		return DefaultProfileName
+	}
+	return c.DefaultProfile
+}
+
+// WithProfile returns a copy of the config whose Windows are those of the named profile
+// An empty name selects the config's default profile
+func (c *Config) WithProfile(name string) (*Config, error) {
+	if name == "" {
+		name = c.DefaultProfileOrDefault()
+	}
+
+	resolved := *c
+	if name == DefaultProfileName {
+		return &resolved, nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q is not defined (available: %v)", name, c.ProfileNames())
+	}
+
+	resolved.Windows = append([]WindowConfig(nil), profile.Windows...)
+	return &resolved, nil
+}
@@ -0,0 +1,110 @@
+package config
+
+// ConfigSource describes where the effective config for a repository came from
+type ConfigSource string
+
+const (
+	SourceRepo     ConfigSource = "repo"     // repo-level config file
+	SourceInTree   ConfigSource = "in-tree"  // trusted .tmux-sessionizer config committed to the repo
+	SourceRule     ConfigSource = "rule"     // global config, profile chosen by a path rule
+	SourceDetected ConfigSource = "detected" // profile or built-in template chosen by project type
+	SourceGlobal   ConfigSource = "global"   // global config file
+	SourceDefaults ConfigSource = "defaults" // built-in defaults
+)
+
+// Resolution is the outcome of choosing a config for a repository
+type Resolution struct {
+	Config *Config
+	Source ConfigSource
+	Path   string // config file the config was read from; empty for defaults
+
+	// Rule is the most specific matching rule when Source is SourceRule
+	Rule *RuleMatch
+	// ProjectType is the detected project type when Source is SourceDetected
+	ProjectType string
+	// InTree is the repository's committed config, if any; when it is not trusted
+	// it was skipped and the resolution fell through to the next source
+	InTree *InTreeConfig
+	// Profile is the profile chosen by the resolution, or empty to use the config's default
+	Profile string
+}
+
+// ResolveConfig determines the effective config for repoDir
+// Precedence: repo config > trusted in-tree config > most specific matching rule >
+// detected project type > global config > defaults
+// A detected project type selects the user's profile of the same name; built-in
+// templates are only used when there is no global config file, so existing layouts
+// are never replaced without the user opting in.
+// repoDir can be empty string to skip repo config and rule checks
+func ResolveConfig(repoDir string) *Resolution {
+	// The global config is read once, up front, as it also says where repo configs live
+	cfg, path := loadGlobalConfig()
+
+	// Try repo-level config first if repoDir is provided
+	if repoDir != "" {
+		if repoPath, err := repoConfigPath(repoDir, cfg.repoConfigStorageOrDefault()); err == nil {
+			if repoCfg, err := loadRepoConfigFile(repoPath); err == nil {
+				return &Resolution{Config: repoCfg, Source: SourceRepo, Path: repoPath}
+			}
+		}
+		// If repo config fails, fall through to global config
+	}
+
+	// Then a committed in-tree config, but only once its content has been trusted
+	var inTree *InTreeConfig
+	if repoDir != "" {
+		inTree, _ = ReadInTreeConfig(repoDir)
+		if inTree != nil && inTree.Trusted {
+			if cfg, err := inTree.Config(); err == nil {
+				return &Resolution{Config: cfg, Source: SourceInTree, Path: inTree.Path, InTree: inTree}
+			}
+		}
+	}
+
+	// Fall back to global config (which has its own fallback to defaults)
+	resolution := &Resolution{Config: cfg, Source: SourceGlobal, Path: path, InTree: inTree}
+	if path == "" {
+		resolution.Source = SourceDefaults
+	}
+
+	if repoDir == "" {
+		return resolution
+	}
+
+	// Let the most specific path rule pick the profile
+	if match := cfg.MatchRule(repoDir); match != nil {
+		ruled := *cfg
+		ruled.DefaultProfile = match.Rule.Profile
+		resolution.Config = &ruled
+		resolution.Source = SourceRule
+		resolution.Rule = match
+		resolution.Profile = match.Rule.Profile
+		return resolution
+	}
+
+	// Let the project type pick the profile
+	if cfg.AutoDetectEnabled() {
+		if projectType := DetectProjectType(repoDir); projectType != "" {
+			detected := *cfg
+			if _, ok := cfg.Profiles[projectType]; !ok {
+				windows, ok := BuiltinTemplate(projectType)
+				if !ok || path != "" {
+					return resolution
+				}
+				// Expose the built-in template as a profile of the effective config
+				detected.Profiles = make(map[string]Profile, len(cfg.Profiles)+1)
+				for name, profile := range cfg.Profiles {
+					detected.Profiles[name] = profile
+				}
+				detected.Profiles[projectType] = Profile{Windows: windows}
+			}
+			detected.DefaultProfile = projectType
+			resolution.Config = &detected
+			resolution.Source = SourceDetected
+			resolution.ProjectType = projectType
+			resolution.Profile = projectType
+		}
+	}
+
+	return resolution
+}
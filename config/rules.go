@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Rule maps repositories whose path matches Pattern to a profile
+// Patterns are slash-separated globs: * matches within a path segment and **
+// matches any number of segments. Patterns starting with / or ~ are anchored to
+// the filesystem root; all others match the end of the repository path.
+type Rule struct {
+	Pattern string `json:"pattern" yaml:"pattern" toml:"pattern"`
+	Profile string `json:"profile" yaml:"profile" toml:"profile"`
+}
+
+// RuleMatch describes a rule that matched a repository path
+type RuleMatch struct {
+	Rule        Rule
+	Index       int // position of the rule in Config.Rules
+	Specificity int
+}
+
+// String formats the rule as "pattern -> profile"
+func (r Rule) String() string {
+	return fmt.Sprintf("%s -> %s", r.Pattern, r.Profile)
+}
+
+// validateRules checks rule patterns and that every rule refers to a known profile
+func (c *Config) validateRules() error {
+	for i, rule := range c.Rules {
+		if rule.Pattern == "" {
+			return fmt.Errorf("rule %d: pattern cannot be empty", i)
+		}
+		for _, segment := range patternSegments(rule.Pattern) {
+			if _, err := filepath.Match(segment, ""); err != nil {
+				return fmt.Errorf("rule %d: invalid pattern %q: %w", i, rule.Pattern, err)
+			}
+		}
+		if rule.Profile == "" {
+			return fmt.Errorf("rule %d: profile cannot be empty", i)
+		}
+		if rule.Profile != DefaultProfileName {
+			if _, ok := c.Profiles[rule.Profile]; !ok {
+				return fmt.Errorf("rule %d: profile %q is not defined", i, rule.Profile)
+			}
+		}
+	}
+	return nil
+}
+
+// MatchRules returns every rule matching repoDir, most specific first
+// Ties keep the order in which the rules were declared
+func (c *Config) MatchRules(repoDir string) []RuleMatch {
+	absDir, err := filepath.Abs(repoDir)
+	if err != nil {
+		return nil
+	}
+	pathSegments := splitPath(absDir)
+
+	var matches []RuleMatch
+	for i, rule := range c.Rules {
+		if !matchPattern(rule.Pattern, pathSegments) {
+			continue
+		}
+		match := RuleMatch{Rule: rule, Index: i, Specificity: patternSpecificity(rule.Pattern)}
+
+		// Insertion sort keeps declaration order among equally specific rules
+		pos := len(matches)
+		for pos > 0 && matches[pos-1].Specificity < match.Specificity {
+			pos--
+		}
+		matches = append(matches, RuleMatch{})
+		copy(matches[pos+1:], matches[pos:])
+		matches[pos] = match
+	}
+
+	return matches
+}
+
+// MatchRule returns the most specific rule matching repoDir, or nil
+func (c *Config) MatchRule(repoDir string) *RuleMatch {
+	matches := c.MatchRules(repoDir)
+	if len(matches) == 0 {
+		return nil
+	}
+	return &matches[0]
+}
+
+// MatchPathPattern reports whether path matches a rule-style glob pattern
+func MatchPathPattern(pattern string, path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	return matchPattern(pattern, splitPath(absPath))
+}
+
+// isAnchoredPattern reports whether a pattern is matched from the filesystem root
+func isAnchoredPattern(pattern string) bool {
+	return strings.HasPrefix(pattern, "/") || pattern == "~" || strings.HasPrefix(pattern, "~/")
+}
+
+// patternSegments splits a pattern into path segments, expanding a leading ~
+func patternSegments(pattern string) []string {
+	return splitPath(expandHome(pattern))
+}
+
+// splitPath splits a path into its non-empty slash-separated segments
+func splitPath(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(filepath.ToSlash(path), "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// matchPattern reports whether the path segments match the pattern
+func matchPattern(pattern string, pathSegments []string) bool {
+	segments := patternSegments(pattern)
+	if !isAnchoredPattern(pattern) {
+		// Unanchored patterns may match any trailing part of the path
+		segments = append([]string{"**"}, segments...)
+	}
+	return matchSegments(segments, pathSegments)
+}
+
+// matchSegments matches glob segments against path segments, with ** spanning any number of segments
+func matchSegments(pattern []string, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(path); skip++ {
+			if matchSegments(pattern[1:], path[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+
+	ok, err := filepath.Match(pattern[0], path[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], path[1:])
+}
+
+// patternSpecificity scores a pattern so that more specific patterns sort first
+// Each segment other than ** counts most, then each literal (non-wildcard) character
+func patternSpecificity(pattern string) int {
+	score := 0
+	for _, segment := range patternSegments(pattern) {
+		if segment == "**" {
+			continue
+		}
+		score += 1000
+		for _, r := range segment {
+			if !strings.ContainsRune(`*?[]\`, r) {
+				score++
+			}
+		}
+	}
+	return score
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaID is the draft the generated schema declares
+const SchemaID = "http://json-schema.org/draft-07/schema#"
+
+// schemaDescriptions documents config fields, keyed by "Type.field" (JSON name)
+// Fields without an entry are still included in the schema, just undocumented
+var schemaDescriptions = map[string]string{
+	"Config.$schema":               "JSON Schema used by editors for completion and validation; ignored by tmux-sessionizer",
+	"Config.version":               "Config format version",
+	"Config.windows":               "Windows opened for the default profile, in order",
+	"Config.default_profile":       "Profile used when no flag, rule or detected project type picks one",
+	"Config.profiles":              "Named layout profiles, selectable with --profile",
+	"Config.rules":                 "Path-pattern rules mapping repositories to profiles; the most specific match wins",
+	"Config.auto_detect":           "Pick a layout from the detected project type when no repo config exists",
+	"Config.repo_config_storage":   "Where repo-level configs live",
+	"Config.include":               "Config fragments merged beneath this file; relative paths resolve from this file",
+	"Config.env_files":             "Dotenv files, relative to the repo, loaded into the session environment",
+	"Config.env":                   "Session environment variables, applied after env_files",
+	"Config.hosts":                 "Sections merged in only on machines matching if_host and/or if_env",
+	"HostOverride.if_host":         "Hostname or glob, matched against the full hostname or its first label ($TMUX_SESSIONIZER_HOST overrides the hostname)",
+	"HostOverride.if_env":          "Environment variable that must be set (VAR) or equal a value (VAR=value)",
+	"HostOverride.windows":         "Windows replacing same-named top-level windows, otherwise appended",
+	"HostOverride.profiles":        "Profiles replacing same-named profiles",
+	"HostOverride.default_profile": "Default profile on matching machines",
+	"HostOverride.rules":           "Path rules appended to the top-level rules",
+	"HostOverride.env_files":       "Dotenv files appended to the top-level env_files",
+	"HostOverride.env":             "Session environment variables overriding same-named top-level ones",
+	"Config.ui":                    "Interactive UI preferences; only read from the global config",
+	"UIConfig.picker_mode":         "insert: typing always searches; vim: j/k navigate until i or / starts a search",
+	"UIConfig.keymap":              "Keys for named actions (" + strings.Join(KeymapActions, ", ") + ")",
+	"UIConfig.theme":               "Colors for the interactive UI; $NO_COLOR disables colors regardless",
+	"UIConfig.sort_running_first":  "List repos with a running tmux session at the top of the picker",
+	"ThemeConfig.name":             "Built-in theme the colors below are layered on",
+	"ThemeConfig.title":            "Headings (hex #rrggbb/#rgb or ANSI 0-255)",
+	"ThemeConfig.cursor":           "Highlighted row (hex #rrggbb/#rgb or ANSI 0-255)",
+	"ThemeConfig.match":            "Characters matched by the search (hex #rrggbb/#rgb or ANSI 0-255)",
+	"ThemeConfig.configured":       "[configured] indicators and success messages (hex #rrggbb/#rgb or ANSI 0-255)",
+	"ThemeConfig.error":            "Errors and warnings (hex #rrggbb/#rgb or ANSI 0-255)",
+	"ThemeConfig.muted":            "Help text, scroll indicators and borders (hex #rrggbb/#rgb or ANSI 0-255)",
+	"WindowConfig.name":            "tmux window name",
+	"WindowConfig.command":         "Command sent to the window; supports ${repo}, ${path}, ${session}, ${branch} and ${env:VAR}",
+	"WindowConfig.if_exists":       "Only open the window when this file or glob exists in the repo",
+	"WindowConfig.if_command":      "Only open the window when this executable is on $PATH",
+	"WindowConfig.if_branch":       "Only open the window when the current branch matches this name or glob",
+	"WindowConfig.env_files":       "Dotenv files, relative to the repo, loaded for this window only",
+	"WindowConfig.env":             "Environment variables for this window only",
+	"Profile.extends":              "Profile whose windows this profile builds on",
+	"Profile.windows":              "Windows opened for this profile",
+	"Rule.pattern":                 "Path glob; ** matches across directories and patterns without a leading / or ~ match anywhere",
+	"Rule.profile":                 "Profile applied to matching repositories",
+}
+
+// schemaEnums restricts fields to a fixed set of values, keyed like schemaDescriptions
+var schemaEnums = map[string][]string{
+	"Config.repo_config_storage": {RepoStorageGit, RepoStorageCentral},
+	"UIConfig.picker_mode":       {PickerModeInsert, PickerModeVim},
+	"ThemeConfig.name":           {ThemeAuto, ThemeDark, ThemeLight, ThemeNone},
+}
+
+// schemaRequired lists the fields a config file must set, keyed like schemaDescriptions
+// Top-level windows aren't required because include fragments may omit them.
+var schemaRequired = map[string]bool{
+	"WindowConfig.name": true,
+	"Rule.pattern":      true,
+	"Rule.profile":      true,
+}
+
+// JSONSchema returns a JSON Schema describing the config file format
+// It is generated from the Config type so new fields are picked up automatically.
+func JSONSchema() ([]byte, error) {
+	gen := &schemaGenerator{defs: make(map[string]any)}
+
+	root, err := gen.object(reflect.TypeOf(Config{}))
+	if err != nil {
+		return nil, err
+	}
+	root["$schema"] = SchemaID
+	root["title"] = "tmux-sessionizer config"
+	root["definitions"] = gen.defs
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// schemaGenerator builds schema nodes, collecting nested struct types as definitions
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+// object returns the schema for a struct type
+// Unknown keys are rejected so typos are caught.
+func (g *schemaGenerator) object(t reflect.Type) (map[string]any, error) {
+	properties := make(map[string]any)
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		node, err := g.node(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+		key := t.Name() + "." + name
+		if description, ok := schemaDescriptions[key]; ok {
+			node["description"] = description
+		}
+		if values, ok := schemaEnums[key]; ok {
+			node["enum"] = values
+		}
+		properties[name] = node
+
+		if schemaRequired[key] {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// node returns the schema for any supported field type
+func (g *schemaGenerator) node(t reflect.Type) (map[string]any, error) {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.node(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := g.node(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Map:
+		values, err := g.node(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		// Nested structs are shared definitions so they're described once
+		if _, ok := g.defs[t.Name()]; !ok {
+			g.defs[t.Name()] = nil // reserve the name while recursing
+			def, err := g.object(t)
+			if err != nil {
+				return nil, err
+			}
+			g.defs[t.Name()] = def
+		}
+		return map[string]any{"$ref": "#/definitions/" + t.Name()}, nil
+	}
+	return nil, fmt.Errorf("unsupported field type %s", t)
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestJSONSchemaInSync checks that every config field is in the generated schema
+// under its JSON name, with a description
+func TestJSONSchemaInSync(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema() failed: %v", err)
+	}
+	if !json.Valid(data) {
+		t.Fatalf("JSONSchema() returned invalid JSON")
+	}
+
+	var schema struct {
+		Properties  map[string]any `json:"properties"`
+		Definitions map[string]struct {
+			Properties map[string]any `json:"properties"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+
+	types := []reflect.Type{
+		reflect.TypeOf(Config{}),
+		reflect.TypeOf(WindowConfig{}),
+		reflect.TypeOf(Profile{}),
+		reflect.TypeOf(Rule{}),
+		reflect.TypeOf(HostOverride{}),
+		reflect.TypeOf(UIConfig{}),
+		reflect.TypeOf(ThemeConfig{}),
+	}
+	for _, typ := range types {
+		properties := schema.Properties
+		if typ != reflect.TypeOf(Config{}) {
+			definition, ok := schema.Definitions[typ.Name()]
+			if !ok {
+				t.Errorf("schema has no definition for %s", typ.Name())
+				continue
+			}
+			properties = definition.Properties
+		}
+
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			key := typ.Name() + "." + name
+			if _, ok := properties[name]; !ok {
+				t.Errorf("schema is missing %s", key)
+			}
+			if _, ok := schemaDescriptions[key]; !ok {
+				t.Errorf("schemaDescriptions has no entry for %s", key)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrConfigChanged is returned by checked saves when the file on disk no longer
+// matches the snapshot taken when it was loaded
+var ErrConfigChanged = errors.New("config file changed on disk since it was loaded")
+
+// Snapshot identifies the on-disk version of a config file
+type Snapshot struct {
+	Path   string
+	Exists bool
+	Hash   string
+}
+
+// takeSnapshot records the current content hash of path
+func takeSnapshot(path string) (Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Snapshot{Path: path}, nil
+	}
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read config: %w", err)
+	}
+	return Snapshot{Path: path, Exists: true, Hash: hashContent(data)}, nil
+}
+
+// matches reports whether two snapshots describe the same file content
+func (s Snapshot) matches(other Snapshot) bool {
+	return s.Exists == other.Exists && s.Hash == other.Hash
+}
+
+// SnapshotConfig records the current version of the global config file
+// Take it before loading, then pass it to SaveConfigChecked
+func SnapshotConfig() (Snapshot, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return takeSnapshot(configPath)
+}
+
+// SnapshotRepoConfig records the current version of a repository's local config file
+// Take it before loading, then pass it to SaveRepoConfigChecked
+func SnapshotRepoConfig(repoDir string) (Snapshot, error) {
+	configPath, err := GetRepoConfigPath(repoDir)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return takeSnapshot(configPath)
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// inTreeConfigBase is the file name (without extension) of a committed repo config
+const inTreeConfigBase = ".tmux-sessionizer"
+
+// trustStore records which in-tree config files the user has approved
+// Files maps the absolute config path to the SHA-256 of the approved content
+type trustStore struct {
+	Files map[string]string `json:"files"`
+}
+
+// InTreeConfig is a committed .tmux-sessionizer config file and its trust state
+type InTreeConfig struct {
+	Path    string
+	Data    []byte
+	Hash    string
+	Trusted bool
+}
+
+// FindInTreeConfig returns the committed config file in a repository's root, if any
+func FindInTreeConfig(repoDir string) (string, bool) {
+	absDir, err := filepath.Abs(repoDir)
+	if err != nil {
+		return "", false
+	}
+	return findConfigFile(absDir, inTreeConfigBase)
+}
+
+// ReadInTreeConfig reads a repository's committed config and checks it against the trust store
+// Returns nil if the repository has no in-tree config
+func ReadInTreeConfig(repoDir string) (*InTreeConfig, error) {
+	path, found := FindInTreeConfig(repoDir)
+	if !found {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	inTree := &InTreeConfig{Path: path, Data: data, Hash: hashContent(data)}
+
+	store, err := loadTrustStore()
+	if err != nil {
+		return nil, err
+	}
+	inTree.Trusted = store.Files[path] == inTree.Hash
+
+	return inTree, nil
+}
+
+// Config parses the in-tree config
+// The data that was hashed is parsed, so a file changing after the trust check is never used.
+// Includes are rejected because the trusted hash wouldn't cover the included files.
+func (t *InTreeConfig) Config() (*Config, error) {
+	raw, err := decodeConfig(t.Path, t.Data)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw.Include) > 0 {
+		return nil, fmt.Errorf("include is not allowed in %s configs", inTreeConfigBase)
+	}
+
+	return parseConfig(t.Path, t.Data)
+}
+
+// PreviouslyTrusted returns the last approved content of this file, if any
+func (t *InTreeConfig) PreviouslyTrusted() ([]byte, bool) {
+	store, err := loadTrustStore()
+	if err != nil {
+		return nil, false
+	}
+
+	hash, ok := store.Files[t.Path]
+	if !ok {
+		return nil, false
+	}
+
+	dir, err := trustedContentDir()
+	if err != nil {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, hash))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Trust records the in-tree config's current content as approved
+func (t *InTreeConfig) Trust() error {
+	if _, err := t.Config(); err != nil {
+		return fmt.Errorf("refusing to trust invalid config: %w", err)
+	}
+
+	dir, err := trustedContentDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create trust directory: %w", err)
+	}
+
+	// Keep a copy of the approved content to diff against when it changes
+	if err := writeFileAtomic(filepath.Join(dir, t.Hash), t.Data, 0600); err != nil {
+		return err
+	}
+
+	err = updateTrustStore(func(store *trustStore) bool {
+		store.Files[t.Path] = t.Hash
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	t.Trusted = true
+	return nil
+}
+
+// UntrustInTreeConfig removes a repository's committed config from the trust store
+func UntrustInTreeConfig(repoDir string) error {
+	path, found := FindInTreeConfig(repoDir)
+	if !found {
+		return fmt.Errorf("no %s config found in %s", inTreeConfigBase, repoDir)
+	}
+
+	return updateTrustStore(func(store *trustStore) bool {
+		if _, ok := store.Files[path]; !ok {
+			return false
+		}
+		delete(store.Files, path)
+		return true
+	})
+}
+
+// hashContent returns the hex SHA-256 of data
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// trustStorePath returns the location of the trust store in the state directory
+func trustStorePath() (string, error) {
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "trust.json"), nil
+}
+
+// trustedContentDir returns the directory holding copies of approved configs
+func trustedContentDir() (string, error) {
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "trusted"), nil
+}
+
+// loadTrustStore reads the trust store, returning an empty store if none exists
+func loadTrustStore() (*trustStore, error) {
+	store := &trustStore{Files: make(map[string]string)}
+
+	path, err := trustStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trust store: %w", err)
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse trust store %s: %w", path, err)
+	}
+	if store.Files == nil {
+		store.Files = make(map[string]string)
+	}
+	return store, nil
+}
+
+// updateTrustStore applies update to the trust store under the state directory lock,
+// so concurrent trust changes from other processes aren't lost
+// The store is only written if update reports a change
+func updateTrustStore(update func(store *trustStore) bool) error {
+	path, err := trustStorePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	unlock, err := lockDir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	store, err := loadTrustStore()
+	if err != nil {
+		return err
+	}
+	if !update(store) {
+		return nil
+	}
+	return saveTrustStore(path, store)
+}
+
+// saveTrustStore writes the trust store to path
+func saveTrustStore(path string, store *trustStore) error {
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal trust store: %w", err)
+	}
+	return writeFileAtomic(path, data, 0600)
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Picker input modes
+const (
+	PickerModeInsert = "insert" // typing always edits the search query
+	PickerModeVim    = "vim"    // normal mode navigates with j/k; i or / switches to typing
+)
+
+// UIConfig holds interactive UI preferences; only the global config's ui section is used
+type UIConfig struct {
+	PickerMode string `json:"picker_mode,omitempty" yaml:"picker_mode,omitempty" toml:"picker_mode,omitempty"`
+
+	// Keymap replaces the keys of named actions (see KeymapActions), e.g. "quit": ["q", "ctrl+c"]
+	Keymap map[string][]string `json:"keymap,omitempty" yaml:"keymap,omitempty" toml:"keymap,omitempty"`
+
+	Theme *ThemeConfig `json:"theme,omitempty" yaml:"theme,omitempty" toml:"theme,omitempty"`
+
+	// SortRunningFirst lists repos that have a running tmux session at the top of the picker
+	SortRunningFirst *bool `json:"sort_running_first,omitempty" yaml:"sort_running_first,omitempty" toml:"sort_running_first,omitempty"`
+}
+
+// Built-in theme names
+const (
+	ThemeAuto  = "auto"  // light or dark to match the terminal background
+	ThemeDark  = "dark"  // for dark terminal backgrounds
+	ThemeLight = "light" // for light terminal backgrounds
+	ThemeNone  = "none"  // no colors, as when $NO_COLOR is set
+)
+
+// ThemeConfig picks a built-in theme and optionally overrides its colors
+// Colors are hex ("#ff5f87", "#f58") or ANSI 256-color numbers ("205").
+type ThemeConfig struct {
+	Name       string `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	Title      string `json:"title,omitempty" yaml:"title,omitempty" toml:"title,omitempty"`
+	Cursor     string `json:"cursor,omitempty" yaml:"cursor,omitempty" toml:"cursor,omitempty"`
+	Match      string `json:"match,omitempty" yaml:"match,omitempty" toml:"match,omitempty"`
+	Configured string `json:"configured,omitempty" yaml:"configured,omitempty" toml:"configured,omitempty"`
+	Error      string `json:"error,omitempty" yaml:"error,omitempty" toml:"error,omitempty"`
+	Muted      string `json:"muted,omitempty" yaml:"muted,omitempty" toml:"muted,omitempty"`
+}
+
+// colorPattern matches the color formats accepted in a theme
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3}|[0-9]{1,3})$`)
+
+// colors returns the theme's color fields keyed by their config names
+func (t *ThemeConfig) colors() map[string]*string {
+	return map[string]*string{
+		"title":      &t.Title,
+		"cursor":     &t.Cursor,
+		"match":      &t.Match,
+		"configured": &t.Configured,
+		"error":      &t.Error,
+		"muted":      &t.Muted,
+	}
+}
+
+// validate checks the theme name and color formats
+func (t *ThemeConfig) validate() error {
+	switch t.Name {
+	case "", ThemeAuto, ThemeDark, ThemeLight, ThemeNone:
+	default:
+		return fmt.Errorf("ui.theme.name must be %q, %q, %q or %q, got %q", ThemeAuto, ThemeDark, ThemeLight, ThemeNone, t.Name)
+	}
+
+	for name, color := range t.colors() {
+		if *color == "" {
+			continue
+		}
+		if !colorPattern.MatchString(*color) {
+			return fmt.Errorf("ui.theme.%s: invalid color %q (expected #rrggbb, #rgb or 0-255)", name, *color)
+		}
+		if n, err := strconv.Atoi(*color); err == nil && n > 255 {
+			return fmt.Errorf("ui.theme.%s: ANSI color %d is out of range (0-255)", name, n)
+		}
+	}
+	return nil
+}
+
+// merge layers the set fields of other on top of t
+func (t *ThemeConfig) merge(other *ThemeConfig) {
+	if other.Name != "" {
+		t.Name = other.Name
+	}
+	otherColors := other.colors()
+	for name, color := range t.colors() {
+		if value := *otherColors[name]; value != "" {
+			*color = value
+		}
+	}
+}
+
+// PickerModeOrDefault returns the configured picker mode, defaulting to insert mode
+func (u *UIConfig) PickerModeOrDefault() string {
+	if u == nil || u.PickerMode == "" {
+		return PickerModeInsert
+	}
+	return u.PickerMode
+}
+
+// SortRunningFirstEnabled reports whether running sessions are sorted to the top (off by default)
+func (u *UIConfig) SortRunningFirstEnabled() bool {
+	return u != nil && u.SortRunningFirst != nil && *u.SortRunningFirst
+}
+
+// validateUI checks the ui section's values
+func (c *Config) validateUI() error {
+	if c.UI == nil {
+		return nil
+	}
+	switch c.UI.PickerMode {
+	case "", PickerModeInsert, PickerModeVim:
+	default:
+		return fmt.Errorf("ui.picker_mode must be %q or %q, got %q", PickerModeInsert, PickerModeVim, c.UI.PickerMode)
+	}
+
+	if c.UI.Theme != nil {
+		if err := c.UI.Theme.validate(); err != nil {
+			return err
+		}
+	}
+
+	for action, keys := range c.UI.Keymap {
+		if !slices.Contains(KeymapActions, action) {
+			return fmt.Errorf("ui.keymap: unknown action %q (expected one of %s)", action, strings.Join(KeymapActions, ", "))
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("ui.keymap: action %q needs at least one key", action)
+		}
+		for _, key := range keys {
+			if key == "" {
+				return fmt.Errorf("ui.keymap: action %q has an empty key", action)
+			}
+		}
+	}
+	return nil
+}
+
+// merge layers the set fields of other on top of u
+func (u *UIConfig) merge(other *UIConfig) {
+	if other.PickerMode != "" {
+		u.PickerMode = other.PickerMode
+	}
+	if len(other.Keymap) > 0 {
+		keymap := make(map[string][]string, len(u.Keymap)+len(other.Keymap))
+		for action, keys := range u.Keymap {
+			keymap[action] = keys
+		}
+		for action, keys := range other.Keymap {
+			keymap[action] = keys
+		}
+		u.Keymap = keymap
+	}
+	if other.Theme != nil {
+		theme := &ThemeConfig{}
+		if u.Theme != nil {
+			*theme = *u.Theme
+		}
+		theme.merge(other.Theme)
+		u.Theme = theme
+	}
+	if other.SortRunningFirst != nil {
+		u.SortRunningFirst = other.SortRunningFirst
+	}
+}
+
+// KeymapActions lists the action names accepted in ui.keymap
+// Each names a field of ui.KeyMap ("move-up" is MoveUp) and is listed in the schema.
+var KeymapActions = []string{
+	"up", "down", "page-up", "page-down", "top", "bottom", "select", "quit", "cancel",
+	"search", "clear-search",
+	"add", "edit", "delete", "move-up", "move-down", "undo", "save",
+	"next-field", "history-prev", "history-next",
+	"overwrite", "reload", "keep-editing",
+}
@@ -0,0 +1,525 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	config "github.com/Haptic-Labs/tmux-sessionizer/config"
+)
+
+const configUsage = "config <list|add-window|remove-window|move-window|set|history|restore> [--repo <path>] [--profile <name>] [args]"
+
+// configSubcommands maps `config <name>` to its implementation
+var configSubcommands = map[string]func(args []string) error{
+	"list":          runConfigList,
+	"add-window":    runConfigAddWindow,
+	"remove-window": runConfigRemoveWindow,
+	"move-window":   runConfigMoveWindow,
+	"set":           runConfigSet,
+	"history":       runConfigHistory,
+	"restore":       runConfigRestore,
+}
+
+// runConfig dispatches the non-interactive config subcommands
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s", configUsage)
+	}
+
+	run, ok := configSubcommands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown config command %q\nusage: %s", args[0], configUsage)
+	}
+	return run(args[1:])
+}
+
+// configTarget identifies the config file (and optionally profile) a config subcommand edits
+type configTarget struct {
+	RepoDir string
+	Profile string
+}
+
+// addFlags registers --repo and --profile on a subcommand's flag set
+func (t *configTarget) addFlags(fs *flag.FlagSet, withProfile bool) {
+	fs.StringVar(&t.RepoDir, "repo", "", "Edit the repo-level config of this repository instead of the global config")
+	if withProfile {
+		fs.StringVar(&t.Profile, "profile", "", "Edit the windows of this profile instead of the top-level windows")
+	}
+}
+
+// resolve validates the --repo flag after parsing
+func (t *configTarget) resolve() error {
+	if t.RepoDir == "" {
+		return nil
+	}
+	repoDir, err := resolveRepoDir(t.RepoDir)
+	if err != nil {
+		return err
+	}
+	t.RepoDir = repoDir
+	return nil
+}
+
+// load reads the targeted config along with a snapshot of the file it came from
+// A repository without a repo config starts from the global config; the config UI loads through here too
+func (t configTarget) load() (*config.Config, config.Snapshot, error) {
+	// Snapshot before reading so a concurrent write in between is caught on save
+	var snapshot config.Snapshot
+	var err error
+	if t.RepoDir != "" {
+		snapshot, err = config.SnapshotRepoConfig(t.RepoDir)
+	} else {
+		snapshot, err = config.SnapshotConfig()
+	}
+	if err != nil {
+		return nil, config.Snapshot{}, err
+	}
+
+	var cfg *config.Config
+	if t.RepoDir != "" {
+		if config.HasRepoConfig(t.RepoDir) {
+			cfg, err = config.LoadRepoConfigForEdit(t.RepoDir)
+		} else {
+			// Start from the global config as written
+			cfg, err = config.LoadGlobalConfigForRepo(t.RepoDir)
+		}
+	} else {
+		cfg, err = config.LoadConfigForEdit()
+	}
+	return cfg, snapshot, err
+}
+
+// path returns the targeted config file
+func (t configTarget) path() (string, error) {
+	if t.RepoDir != "" {
+		return config.GetRepoConfigPath(t.RepoDir)
+	}
+	return config.GetConfigPath()
+}
+
+// save writes the targeted config, refusing to overwrite changes made since snapshot
+func (t configTarget) save(cfg *config.Config, snapshot config.Snapshot) error {
+	var err error
+	if t.RepoDir != "" {
+		err = config.SaveRepoConfigChecked(t.RepoDir, cfg, snapshot)
+	} else {
+		err = config.SaveConfigChecked(cfg, snapshot)
+	}
+	if errors.Is(err, config.ErrConfigChanged) {
+		return fmt.Errorf("%w; re-run the command to apply it to the new version", err)
+	}
+	return err
+}
+
+// editWindows loads the config, applies edit to the targeted window list and saves the result
+func (t configTarget) editWindows(edit func(windows []config.WindowConfig) ([]config.WindowConfig, error)) error {
+	cfg, snapshot, err := t.load()
+	if err != nil {
+		return err
+	}
+
+	// Edit the inherited windows rather than replacing them with a list of just the edit
+	path, err := t.path()
+	if err != nil {
+		return err
+	}
+	from, err := cfg.InheritIncludedWindows(path, t.Profile)
+	if err != nil {
+		return err
+	}
+	switch {
+	case from == "":
+	case t.Profile == "" || t.Profile == config.DefaultProfileName:
+		fmt.Printf("Copied the windows inherited from %s into this config, which now sets them itself\n", from)
+	default:
+		fmt.Printf("Copied profile %s inherited from %s into this config, which now sets it itself\n", t.Profile, from)
+	}
+
+	if t.Profile == "" || t.Profile == config.DefaultProfileName {
+		cfg.Windows, err = edit(cfg.Windows)
+		if err != nil {
+			return err
+		}
+		return t.save(cfg, snapshot)
+	}
+
+	profile, ok := cfg.Profiles[t.Profile]
+	if !ok {
+		return fmt.Errorf("profile %q is not defined", t.Profile)
+	}
+	profile.Windows, err = edit(profile.Windows)
+	if err != nil {
+		return err
+	}
+	cfg.Profiles[t.Profile] = profile
+	return t.save(cfg, snapshot)
+}
+
+// findWindow resolves a window reference given as an index or a name
+func findWindow(windows []config.WindowConfig, ref string) (int, error) {
+	if index, err := strconv.Atoi(ref); err == nil {
+		if index < 0 || index >= len(windows) {
+			return -1, fmt.Errorf("window index %d out of range (0-%d)", index, len(windows)-1)
+		}
+		return index, nil
+	}
+
+	for i, window := range windows {
+		if window.Name == ref {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no window named %q", ref)
+}
+
+const configListUsage = "config list [--repo <path>]"
+
+// runConfigList prints the targeted config
+func runConfigList(args []string) error {
+	var target configTarget
+	fs := newCommandFlagSet(configListUsage)
+	target.addFlags(fs, false)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := target.resolve(); err != nil {
+		return err
+	}
+
+	cfg, _, err := target.load()
+	if err != nil {
+		return err
+	}
+
+	if target.RepoDir != "" && !config.HasRepoConfig(target.RepoDir) {
+		fmt.Println("# no repo config; showing the global config it would start from")
+	}
+
+	fmt.Printf("version: %s\n", cfg.Version)
+	if cfg.DefaultProfile != "" {
+		fmt.Printf("default_profile: %s\n", cfg.DefaultProfile)
+	}
+	if !cfg.AutoDetectEnabled() {
+		fmt.Println("auto_detect: false")
+	}
+
+	for _, include := range cfg.Include {
+		fmt.Printf("include: %s\n", include)
+	}
+
+	// Show what the includes provide where the config doesn't set it itself
+	path, err := target.path()
+	if err != nil {
+		return err
+	}
+	included, err := config.IncludedLayoutOf(path, cfg)
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Windows) == 0 && len(included.Windows) > 0 {
+		printWindows("windows (from include "+included.WindowsFrom+")", included.Windows)
+	} else {
+		printWindows("windows", cfg.Windows)
+	}
+
+	profiles := make(map[string]config.Profile, len(included.Profiles)+len(cfg.Profiles))
+	for name, profile := range included.Profiles {
+		profiles[name] = profile
+	}
+	for name, profile := range cfg.Profiles {
+		profiles[name] = profile
+	}
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == config.DefaultProfileName {
+			continue
+		}
+		title := "profile " + name
+		if extends := profiles[name].Extends; extends != "" {
+			title += " (extends " + extends + ")"
+		}
+		if _, own := cfg.Profiles[name]; !own {
+			title += " (from include " + included.ProfilesFrom[name] + ")"
+		}
+		printWindows(title, profiles[name].Windows)
+	}
+
+	for i, rule := range cfg.Rules {
+		if i == 0 {
+			fmt.Println("rules:")
+		}
+		fmt.Printf("  %d: %s\n", i, rule)
+	}
+
+	return nil
+}
+
+// printWindows prints a titled, indexed window list
+func printWindows(title string, windows []config.WindowConfig) {
+	fmt.Printf("%s:\n", title)
+	for i, window := range windows {
+		commandDisplay := "<none>"
+		if window.Command != "" {
+			commandDisplay = window.Command
+		}
+		fmt.Printf("  %d: %s (command: %s)\n", i, window.Name, commandDisplay)
+	}
+}
+
+const configAddWindowUsage = "config add-window [--repo <path>] [--profile <name>] --name <name> [--command <cmd>] [--at <index>]"
+
+// runConfigAddWindow appends (or inserts) a window
+func runConfigAddWindow(args []string) error {
+	var target configTarget
+	fs := newCommandFlagSet(configAddWindowUsage)
+	target.addFlags(fs, true)
+	name := fs.String("name", "", "Window name")
+	command := fs.String("command", "", "Command to run in the window")
+	at := fs.Int("at", -1, "Insert at this index instead of appending")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := target.resolve(); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("--name is required")
+	}
+
+	return target.editWindows(func(windows []config.WindowConfig) ([]config.WindowConfig, error) {
+		window := config.WindowConfig{Name: *name, Command: *command}
+		if *at < 0 {
+			return append(windows, window), nil
+		}
+		if *at > len(windows) {
+			return nil, fmt.Errorf("index %d out of range (0-%d)", *at, len(windows))
+		}
+		windows = append(windows, config.WindowConfig{})
+		copy(windows[*at+1:], windows[*at:])
+		windows[*at] = window
+		return windows, nil
+	})
+}
+
+const configRemoveWindowUsage = "config remove-window [--repo <path>] [--profile <name>] <name|index>"
+
+// runConfigRemoveWindow removes a window
+func runConfigRemoveWindow(args []string) error {
+	var target configTarget
+	fs := newCommandFlagSet(configRemoveWindowUsage)
+	target.addFlags(fs, true)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := target.resolve(); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected a window name or index")
+	}
+
+	return target.editWindows(func(windows []config.WindowConfig) ([]config.WindowConfig, error) {
+		index, err := findWindow(windows, fs.Arg(0))
+		if err != nil {
+			return nil, err
+		}
+		if len(windows) == 1 {
+			return nil, fmt.Errorf("cannot delete the last window")
+		}
+		return append(windows[:index], windows[index+1:]...), nil
+	})
+}
+
+const configMoveWindowUsage = "config move-window [--repo <path>] [--profile <name>] <name|index> <new-index>"
+
+// runConfigMoveWindow moves a window to a new position
+func runConfigMoveWindow(args []string) error {
+	var target configTarget
+	fs := newCommandFlagSet(configMoveWindowUsage)
+	target.addFlags(fs, true)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := target.resolve(); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected a window and a new index")
+	}
+	newIndex, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		return fmt.Errorf("invalid index %q", fs.Arg(1))
+	}
+
+	return target.editWindows(func(windows []config.WindowConfig) ([]config.WindowConfig, error) {
+		index, err := findWindow(windows, fs.Arg(0))
+		if err != nil {
+			return nil, err
+		}
+		if newIndex < 0 || newIndex >= len(windows) {
+			return nil, fmt.Errorf("index %d out of range (0-%d)", newIndex, len(windows)-1)
+		}
+		window := windows[index]
+		windows = append(windows[:index], windows[index+1:]...)
+		windows = append(windows[:newIndex], append([]config.WindowConfig{window}, windows[newIndex:]...)...)
+		return windows, nil
+	})
+}
+
+const configSetUsage = "config set [--repo <path>] [--profile <name>] <key> <value>"
+
+// windowFields are the window settings editable with `config set windows.<window>.<field>`
+var windowFields = map[string]func(window *config.WindowConfig, value string){
+	"name":       func(w *config.WindowConfig, v string) { w.Name = v },
+	"command":    func(w *config.WindowConfig, v string) { w.Command = v },
+	"if_exists":  func(w *config.WindowConfig, v string) { w.IfExists = v },
+	"if_command": func(w *config.WindowConfig, v string) { w.IfCommand = v },
+	"if_branch":  func(w *config.WindowConfig, v string) { w.IfBranch = v },
+}
+
+// runConfigSet sets a top-level setting or a window field
+// Keys: version, default_profile, auto_detect, repo_config_storage, windows.<name|index>.<field>
+func runConfigSet(args []string) error {
+	var target configTarget
+	fs := newCommandFlagSet(configSetUsage)
+	target.addFlags(fs, true)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := target.resolve(); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected a key and a value")
+	}
+	key, value := fs.Arg(0), fs.Arg(1)
+
+	// Window fields: windows.<name|index>.<field>
+	if rest, ok := strings.CutPrefix(key, "windows."); ok {
+		dot := strings.LastIndex(rest, ".")
+		if dot == -1 {
+			return fmt.Errorf("expected windows.<name|index>.<field>")
+		}
+		ref, field := rest[:dot], rest[dot+1:]
+		setField, ok := windowFields[field]
+		if !ok {
+			return fmt.Errorf("unknown window field %q", field)
+		}
+		return target.editWindows(func(windows []config.WindowConfig) ([]config.WindowConfig, error) {
+			index, err := findWindow(windows, ref)
+			if err != nil {
+				return nil, err
+			}
+			setField(&windows[index], value)
+			return windows, nil
+		})
+	}
+
+	if target.Profile != "" {
+		return fmt.Errorf("--profile only applies to windows.* keys")
+	}
+
+	cfg, snapshot, err := target.load()
+	if err != nil {
+		return err
+	}
+
+	switch key {
+	case "version":
+		cfg.Version = value
+	case "default_profile":
+		cfg.DefaultProfile = value
+	case "repo_config_storage":
+		cfg.RepoConfigStorage = value
+	case "auto_detect":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("auto_detect must be true or false")
+		}
+		cfg.AutoDetect = &enabled
+	default:
+		return fmt.Errorf("unknown key %q (expected version, default_profile, auto_detect, repo_config_storage or windows.<name|index>.<field>)", key)
+	}
+
+	return target.save(cfg, snapshot)
+}
+
+const configHistoryUsage = "config history [--repo <path>]"
+
+// runConfigHistory lists the saved previous versions of the targeted config
+func runConfigHistory(args []string) error {
+	var target configTarget
+	fs := newCommandFlagSet(configHistoryUsage)
+	target.addFlags(fs, false)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := target.resolve(); err != nil {
+		return err
+	}
+
+	path, err := target.path()
+	if err != nil {
+		return err
+	}
+	entries, err := config.ConfigHistory(path)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No previous versions of %s\n", path)
+		return nil
+	}
+
+	fmt.Printf("Previous versions of %s (newest first):\n", path)
+	for _, entry := range entries {
+		fmt.Printf("  %2d  %s\n", entry.Index, entry.Time.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+const configRestoreUsage = "config restore [--repo <path>] <n>"
+
+// runConfigRestore replaces the targeted config with a previous version from its history
+func runConfigRestore(args []string) error {
+	var target configTarget
+	fs := newCommandFlagSet(configRestoreUsage)
+	target.addFlags(fs, false)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := target.resolve(); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected a version number (see `config history`)")
+	}
+	n, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid version %q", fs.Arg(0))
+	}
+
+	path, err := target.path()
+	if err != nil {
+		return err
+	}
+	if err := config.RestoreConfigVersion(path, n); err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored version %d of %s\n", n, path)
+	return nil
+}
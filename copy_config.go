@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	config "github.com/Haptic-Labs/tmux-sessionizer/config"
+	git "github.com/Haptic-Labs/tmux-sessionizer/git"
+	ui "github.com/Haptic-Labs/tmux-sessionizer/ui"
+	utils "github.com/Haptic-Labs/tmux-sessionizer/utils"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// copyPlan is a repository a config would be written to, and whether it already has one
+type copyPlan struct {
+	RepoDir   string
+	Overwrite bool
+}
+
+// planCopies lists the target repositories (excluding the source) with their current config state
+func planCopies(fromRepo string, targets []string) []copyPlan {
+	var plans []copyPlan
+	for _, target := range targets {
+		if target == fromRepo {
+			continue
+		}
+		plans = append(plans, copyPlan{RepoDir: target, Overwrite: config.HasRepoConfig(target)})
+	}
+	return plans
+}
+
+// printCopyPlan shows which repositories would be created or overwritten
+func printCopyPlan(fromRepo string, plans []copyPlan) {
+	fmt.Printf("Copying repo config from %s to:\n", fromRepo)
+	for _, plan := range plans {
+		action := "create   "
+		if plan.Overwrite {
+			action = "overwrite"
+		}
+		fmt.Printf("  %s %s\n", action, plan.RepoDir)
+	}
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(strings.ToLower(input))
+	return input == "y" || input == "yes"
+}
+
+// applyCopyPlan writes the source repo config to every planned repository
+func applyCopyPlan(fromRepo string, plans []copyPlan) error {
+	failed := 0
+	for _, plan := range plans {
+		if err := config.CopyRepoConfig(fromRepo, plan.RepoDir); err != nil {
+			fmt.Fprintf(os.Stderr, "failed %s: %v\n", plan.RepoDir, err)
+			failed++
+		}
+	}
+
+	fmt.Printf("%d repo config(s) written\n", len(plans)-failed)
+	if failed > 0 {
+		return fmt.Errorf("%d repo config(s) could not be written", failed)
+	}
+	return nil
+}
+
+// requireRepoConfig checks that a source repository has a repo config to copy
+func requireRepoConfig(repoDir string) error {
+	if !config.HasRepoConfig(repoDir) {
+		return fmt.Errorf("%s has no repo config to copy", repoDir)
+	}
+	if _, err := config.LoadRepoConfig(repoDir); err != nil {
+		return err
+	}
+	return nil
+}
+
+const copyConfigUsage = "copy-config [--yes] <from-repo> <to-repo>"
+
+// runCopyConfig copies a repo config from one repository to another
+func runCopyConfig(args []string) error {
+	fs := newCommandFlagSet(copyConfigUsage)
+	yes := fs.Bool("yes", false, "Write without asking for confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected a source and a destination repository")
+	}
+
+	fromRepo, err := resolveRepoDir(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	toRepo, err := resolveRepoDir(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if err := requireRepoConfig(fromRepo); err != nil {
+		return err
+	}
+
+	plans := planCopies(fromRepo, []string{toRepo})
+	if len(plans) == 0 {
+		return fmt.Errorf("source and destination are the same repository")
+	}
+
+	printCopyPlan(fromRepo, plans)
+	if !*yes && !confirm("Write this config?") {
+		return fmt.Errorf("cancelled")
+	}
+	return applyCopyPlan(fromRepo, plans)
+}
+
+const applyConfigUsage = "apply-config [--dir <directory>] [--dry-run] [--yes] <from-repo> <glob>"
+
+// runApplyConfig copies a repo config to every discovered repository matching a glob
+func runApplyConfig(args []string) error {
+	fs := newCommandFlagSet(applyConfigUsage)
+	searchDir := fs.String("dir", ".", "Directory to search for repositories")
+	dryRun := fs.Bool("dry-run", false, "Only list the repositories that would be written")
+	yes := fs.Bool("yes", false, "Write without asking for confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected a source repository and a glob")
+	}
+
+	fromRepo, err := resolveRepoDir(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := requireRepoConfig(fromRepo); err != nil {
+		return err
+	}
+	pattern := fs.Arg(1)
+
+	absSearchDir, err := filepath.Abs(*searchDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+	repos, err := git.FindGitRepos(absSearchDir)
+	if err != nil {
+		return fmt.Errorf("failed to find git repositories: %w", err)
+	}
+
+	var targets []string
+	for _, repo := range repos {
+		if config.MatchPathPattern(pattern, repo) {
+			targets = append(targets, repo)
+		}
+	}
+	sort.Strings(targets)
+
+	plans := planCopies(fromRepo, targets)
+	if len(plans) == 0 {
+		fmt.Printf("No repositories under %s match %q\n", absSearchDir, pattern)
+		return nil
+	}
+
+	printCopyPlan(fromRepo, plans)
+	if *dryRun {
+		return nil
+	}
+	if !*yes && !confirm(fmt.Sprintf("Write this config to %d repositories?", len(plans))) {
+		return fmt.Errorf("cancelled")
+	}
+	return applyCopyPlan(fromRepo, plans)
+}
+
+// runCopyConfigUI lets the user pick a source and destination repository in searchDir
+// and copies the source's repo config after confirmation
+func runCopyConfigUI(searchDir string) {
+	fmt.Printf("Searching for git repositories in: %s\n", searchDir)
+
+	repos, err := git.FindGitRepos(searchDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding git repositories: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Only repositories with a repo config can be copied from
+	var sources []string
+	for _, repo := range repos {
+		if config.HasRepoConfig(repo) {
+			sources = append(sources, repo)
+		}
+	}
+	if len(sources) == 0 {
+		fmt.Println("No repositories with a repo config found.")
+		return
+	}
+
+	fromRepo, ok := pickRepo("Copy repo config from:", sources)
+	if !ok {
+		fmt.Println("No repository selected.")
+		return
+	}
+
+	var targets []string
+	for _, repo := range repos {
+		if repo != fromRepo {
+			targets = append(targets, repo)
+		}
+	}
+	if len(targets) == 0 {
+		fmt.Println("No other repositories found.")
+		return
+	}
+
+	toRepo, ok := pickRepo(fmt.Sprintf("Copy %s's repo config to:", filepath.Base(fromRepo)), targets)
+	if !ok {
+		fmt.Println("No repository selected.")
+		return
+	}
+
+	plans := planCopies(fromRepo, []string{toRepo})
+	printCopyPlan(fromRepo, plans)
+	if !confirm("Write this config?") {
+		return
+	}
+	if err := applyCopyPlan(fromRepo, plans); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// pickRepo shows the repository selector (with configured indicators) and returns the chosen path
+func pickRepo(title string, repos []string) (string, bool) {
+	dirMap := utils.GetDirectoryNames(repos)
+	var options []string
+	for name := range dirMap {
+		options = append(options, name)
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return strings.ToLower(options[i]) < strings.ToLower(options[j])
+	})
+
+	model := ui.InitializeRepoSelectorModel(options, dirMap, true)
+	model.Title = title
+	p := tea.NewProgram(&model)
+	result, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running repo selection: %v\n", err)
+		os.Exit(1)
+	}
+
+	m, ok := result.(*ui.BubbleteaModel)
+	if !ok || m.Selected == -1 {
+		return "", false
+	}
+	return dirMap[options[m.Selected]], true
+}
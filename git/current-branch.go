@@ -0,0 +1,28 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CurrentBranch returns the checked-out branch of the repository at dir
+// For a detached HEAD the abbreviated commit hash is returned; an empty string
+// means the branch could not be determined
+func CurrentBranch(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, ".git", "HEAD"))
+	if err != nil {
+		return ""
+	}
+
+	head := strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+
+	// Detached HEAD contains the commit hash
+	if len(head) > 7 {
+		return head[:7]
+	}
+	return head
+}
@@ -0,0 +1,87 @@
+package git
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OriginURL returns the url of the "origin" remote from the repository's .git/config
+// Returns an empty string if the repository has no origin remote
+func OriginURL(dir string) string {
+	file, err := os.Open(filepath.Join(dir, ".git", "config"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	inOrigin := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+			continue
+		}
+		if !inOrigin {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(key) == "url" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+// NormalizeRemoteURL reduces a remote URL to host/path form so that equivalent
+// remotes compare equal, e.g. git@github.com:org/repo.git and
+// https://github.com/org/repo both become github.com/org/repo
+// Returns an empty string for URLs it can't interpret
+func NormalizeRemoteURL(remote string) string {
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return ""
+	}
+
+	var host, path string
+	if strings.Contains(remote, "://") {
+		parsed, err := url.Parse(remote)
+		if err != nil {
+			return ""
+		}
+		host, path = parsed.Hostname(), parsed.Path
+	} else if colon := strings.Index(remote, ":"); colon > 0 && !strings.Contains(remote[:colon], "/") {
+		// scp-like syntax: [user@]host:path
+		host, path = remote[:colon], remote[colon+1:]
+		if at := strings.LastIndex(host, "@"); at != -1 {
+			host = host[at+1:]
+		}
+	} else {
+		// Local path remote
+		path = remote
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+
+	// Drop empty, "." and ".." segments so the result is safe to use as a relative path
+	var segments []string
+	if host != "" {
+		segments = append(segments, strings.ToLower(host))
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	if len(segments) == 0 {
+		return ""
+	}
+
+	return strings.Join(segments, "/")
+}
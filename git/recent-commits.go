@@ -0,0 +1,26 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// RecentCommits returns up to n one-line summaries ("<hash> <subject> (<age>)") of the
+// latest commits on the current branch of the repository at dir, newest first
+func RecentCommits(dir string, n int) ([]string, error) {
+	cmd := exec.Command("git", "-C", dir, "log", "-n", strconv.Itoa(n), "--format=%h %s (%cr)")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git log: %w", err)
+	}
+
+	var commits []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			commits = append(commits, line)
+		}
+	}
+	return commits, nil
+}
@@ -22,6 +22,8 @@ func main() {
 	var forceRecreate bool
 	var useCurrent bool
 	var configMode bool
+	var configFile string
+	var profileName string
 
 	flag.BoolVar(&forceAttach, "a", false, "Automatically attach to existing session if it exists")
 	flag.BoolVar(&forceAttach, "attach", false, "Automatically attach to existing session if it exists")
@@ -30,10 +32,39 @@ func main() {
 	flag.BoolVar(&useCurrent, "c", false, "Use current directory for session (skip directory selection)")
 	flag.BoolVar(&useCurrent, "current", false, "Use current directory for session (skip directory selection)")
 	flag.BoolVar(&configMode, "config", false, "Open interactive configuration UI")
+	flag.StringVar(&profileName, "p", "", "Layout profile to use for the new session (skips the profile picker)")
+	flag.StringVar(&profileName, "profile", "", "Layout profile to use for the new session (skips the profile picker)")
+	flag.StringVar(&configFile, "config-file", "", "Path to the global config file (overrides $"+config.ConfigFileEnv+")")
 
 	// Parse flags
+	flag.Usage = printUsage
 	flag.Parse()
 
+	if configFile != "" {
+		if err := config.SetConfigFile(configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Dispatch non-interactive subcommands
+	if args := flag.Args(); len(args) > 0 {
+		if cmd := findCommand(args[0]); cmd != nil {
+			if err := cmd.Run(args[1:]); err != nil {
+				if err != flag.ErrHelp {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	// Apply UI preferences from the global config's ui section
+	if globalConfig, err := config.LoadConfig(); err == nil {
+		ui.ApplySettings(globalConfig.UI)
+	}
+
 	// If --config flag is set, launch configuration UI
 	if configMode {
 		if useCurrent {
@@ -51,15 +82,15 @@ func main() {
 				os.Exit(1)
 			}
 
-			// Load or create repo config
-			cfg, err := config.LoadRepoConfig(currentDir)
+			// Load or create repo config, starting from the global config if there is none
+			cfg, snapshot, err := configTarget{RepoDir: currentDir}.load()
 			if err != nil {
-				// No repo config exists, start with global config
-				cfg, _ = config.LoadConfig()
+				fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+				os.Exit(1)
 			}
 
 			// Launch config UI with repo context
-			model := ui.InitializeRepoConfigModel(cfg, currentDir)
+			model := ui.InitializeRepoConfigModel(cfg, currentDir, snapshot)
 			p := tea.NewProgram(&model)
 			_, err = p.Run()
 			if err != nil {
@@ -86,13 +117,13 @@ func main() {
 
 		if cm.Selected == 0 {
 			// Global config selected
-			cfg, err := config.LoadConfig()
+			cfg, snapshot, err := configTarget{}.load()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 				os.Exit(1)
 			}
 
-			model := ui.InitializeConfigModel(cfg)
+			model := ui.InitializeConfigModel(cfg, snapshot)
 			p := tea.NewProgram(&model)
 			_, err = p.Run()
 			if err != nil {
@@ -122,6 +153,12 @@ func main() {
 				searchDir = currentDir
 			}
 
+			if cm.Selected == 2 {
+				// Copy a repo config between repositories
+				runCopyConfigUI(searchDir)
+				return
+			}
+
 			fmt.Printf("Searching for git repositories in: %s\n", searchDir)
 
 			// Find repos
@@ -166,15 +203,15 @@ func main() {
 			selected := options[m.Selected]
 			selectedPath := dirMap[selected]
 
-			// Load or create repo config
-			cfg, err := config.LoadRepoConfig(selectedPath)
+			// Load or create repo config, starting from the global config if there is none
+			cfg, snapshot, err := configTarget{RepoDir: selectedPath}.load()
 			if err != nil {
-				// No repo config exists, start with global config
-				cfg, _ = config.LoadConfig()
+				fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+				os.Exit(1)
 			}
 
 			// Launch config UI with repo context
-			model := ui.InitializeRepoConfigModel(cfg, selectedPath)
+			model := ui.InitializeRepoConfigModel(cfg, selectedPath, snapshot)
 			p = tea.NewProgram(&model)
 			_, err = p.Run()
 			if err != nil {
@@ -196,11 +233,10 @@ func main() {
 		// Get the base name of the current directory for the session name
 		sessionName := filepath.Base(currentDir)
 
-		// Load configuration with repo-level fallback
-		cfg, _ := config.LoadConfigWithFallback(currentDir)
-
 		// Create tmux session directly
-		err = tmux.CreateTmuxSession(sessionName, currentDir, forceAttach, forceRecreate, cfg)
+		err = tmux.CreateTmuxSession(sessionName, currentDir, forceAttach, forceRecreate, func() (*config.Config, bool) {
+			return sessionConfig(currentDir, profileName, sessionName)
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error creating tmux session: %v\n", err)
 			os.Exit(1)
@@ -280,16 +316,64 @@ func main() {
 		os.Exit(0)
 	}
 
-	selected := options[m.Selected]
+	selected := m.Options[m.Selected]
 	selectedPath := dirMap[selected]
 
-	// Load configuration with repo-level fallback
-	cfg, _ := config.LoadConfigWithFallback(selectedPath)
-
 	// Create tmux session
-	err = tmux.CreateTmuxSession(selected, selectedPath, forceAttach, forceRecreate, cfg)
+	err = tmux.CreateTmuxSession(selected, selectedPath, forceAttach, forceRecreate, func() (*config.Config, bool) {
+		return sessionConfig(selectedPath, profileName, selected)
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating tmux session: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// sessionConfig resolves the configuration for a new session in dir: repo config, path rules,
+// then global config, and the layout profile. Returns false if the user cancelled.
+func sessionConfig(dir string, profileName string, sessionName string) (*config.Config, bool) {
+	resolution := config.ResolveConfig(dir)
+	resolution = confirmInTreeConfig(resolution, dir)
+
+	cfg, ok := selectProfile(resolution, profileName, sessionName)
+	if !ok {
+		fmt.Println("No profile selected.")
+	}
+	return cfg, ok
+}
+
+// selectProfile resolves the layout profile for a new session
+// An explicit name (from --profile) or a profile chosen by a path rule is used directly;
+// otherwise the user is asked to choose when more than one profile applies.
+// Returns false if the user cancelled.
+func selectProfile(resolution *config.Resolution, name string, sessionName string) (*config.Config, bool) {
+	cfg := resolution.Config
+	if name == "" {
+		name = resolution.Profile
+	}
+	if name == "" {
+		profiles := cfg.ProfileNames()
+		if len(profiles) > 1 {
+			model := ui.InitializeProfileChoiceModel(sessionName, profiles)
+			p := tea.NewProgram(&model)
+			result, err := p.Run()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error running profile selection: %v\n", err)
+				os.Exit(1)
+			}
+
+			pm, ok := result.(*ui.ProfileChoiceModel)
+			if !ok || pm.Selected == -1 {
+				return nil, false
+			}
+			name = profiles[pm.Selected]
+		}
+	}
+
+	resolved, err := cfg.WithProfile(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return resolved, true
+}
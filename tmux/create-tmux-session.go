@@ -5,20 +5,28 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	config "github.com/Haptic-Labs/tmux-sessionizer/config"
+	git "github.com/Haptic-Labs/tmux-sessionizer/git"
 )
 
 // CreateTmuxSession creates a new tmux session with the specified name and directory
 // If forceAttach is true and a session exists, it will attach to the existing session without prompting
 // If forceRecreate is true and a session exists, it will kill and recreate the session without prompting
-// cfg specifies the window configuration; if nil, defaults will be used
-func CreateTmuxSession(name string, directory string, forceAttach bool, forceRecreate bool, cfg *config.Config) error {
+// loadConfig supplies the window configuration (nil for defaults); it is only called once a session
+// is going to be created, so attaching never prompts for a profile. Returning false cancels.
+func CreateTmuxSession(name string, directory string, forceAttach bool, forceRecreate bool, loadConfig func() (*config.Config, bool)) error {
+	// Use the name tmux would give the session so the existing-session check finds it
+	name = SessionName(name)
+
 	// Check if session already exists
 	checkCmd := exec.Command("tmux", "has-session", "-t", "="+name)
 	err := checkCmd.Run()
 
+	recreate := false
 	if err == nil {
 		// Session exists, determine what to do
 		if forceAttach {
@@ -29,12 +37,8 @@ func CreateTmuxSession(name string, directory string, forceAttach bool, forceRec
 			attachCmd.Stderr = os.Stderr
 			return attachCmd.Run()
 		} else if forceRecreate {
-			// Automatically kill existing session
-			killCmd := exec.Command("tmux", "kill-session", "-t", name)
-			if err := killCmd.Run(); err != nil {
-				return fmt.Errorf("failed to kill existing session: %w", err)
-			}
-			// Continue to create new session below
+			// Automatically kill existing session (below, once the new one is ready to create)
+			recreate = true
 		} else {
 			// Prompt user for action
 			fmt.Printf("Session '%s' already exists. Choose an option:\n", name)
@@ -55,12 +59,8 @@ func CreateTmuxSession(name string, directory string, forceAttach bool, forceRec
 				attachCmd.Stderr = os.Stderr
 				return attachCmd.Run()
 			case "k", "n":
-				// Kill existing session
-				killCmd := exec.Command("tmux", "kill-session", "-t", name)
-				if err := killCmd.Run(); err != nil {
-					return fmt.Errorf("failed to kill existing session: %w", err)
-				}
-				// Continue to create new session below
+				// Kill existing session below, once the new one is ready to create
+				recreate = true
 			case "q", "c", "":
 				// Cancel operation
 				return nil
@@ -71,6 +71,11 @@ func CreateTmuxSession(name string, directory string, forceAttach bool, forceRec
 		}
 	}
 
+	cfg, ok := loadConfig()
+	if !ok {
+		return nil
+	}
+
 	// Use defaults if config is nil
 	if cfg == nil {
 		cfg = config.GetDefaultConfig()
@@ -80,13 +85,59 @@ func CreateTmuxSession(name string, directory string, forceAttach bool, forceRec
 		return fmt.Errorf("no windows configured")
 	}
 
+	// Skip windows whose conditions don't hold for this directory
+	branch := git.CurrentBranch(directory)
+	windows := filterWindows(cfg.Windows, directory, branch)
+	if len(windows) == 0 {
+		return fmt.Errorf("no windows left after evaluating window conditions")
+	}
+
+	// Expand ${...} variables before touching tmux so errors don't leave a partial session
+	windows, err = expandWindowCommands(windows, name, directory, branch)
+	if err != nil {
+		return err
+	}
+
+	// Load session and window environments up front for the same reason
+	sessionEnv, err := loadEnv(directory, cfg.EnvFiles, cfg.Env)
+	if err != nil {
+		return err
+	}
+	windowEnvs := make([]map[string]string, len(windows))
+	for i, window := range windows {
+		windowEnvs[i], err = loadEnv(directory, window.EnvFiles, window.Env)
+		if err != nil {
+			return fmt.Errorf("window %q: %w", window.Name, err)
+		}
+	}
+
+	// Kill the existing session only now, so a cancelled profile choice or a bad config leaves it running
+	if recreate {
+		killCmd := exec.Command("tmux", "kill-session", "-t", name)
+		if err := killCmd.Run(); err != nil {
+			return fmt.Errorf("failed to kill existing session: %w", err)
+		}
+	}
+
 	// Create first window (session creation)
-	firstWindow := cfg.Windows[0]
-	createCmd := exec.Command("tmux", "new-session", "-d", "-s", name, "-c", directory, "-n", firstWindow.Name)
+	firstWindow := windows[0]
+	createArgs := []string{"new-session", "-d", "-s", name, "-c", directory, "-n", firstWindow.Name}
+	createArgs = append(createArgs, envArgs(sessionEnv)...)
+	createCmd := exec.Command("tmux", createArgs...)
 	if err := createCmd.Run(); err != nil {
 		return err
 	}
 
+	// The first window's shell was started by new-session, so restart it with its own overrides
+	if len(windowEnvs[0]) > 0 {
+		respawnArgs := []string{"respawn-window", "-k", "-t", name + ":0", "-c", directory}
+		respawnArgs = append(respawnArgs, envArgs(windowEnvs[0])...)
+		respawnCmd := exec.Command("tmux", respawnArgs...)
+		if err := respawnCmd.Run(); err != nil {
+			return err
+		}
+	}
+
 	// Run command in first window if specified
 	if firstWindow.Command != "" {
 		sendCmd := exec.Command("tmux", "send-keys", "-t", name+":0", firstWindow.Command, "Enter")
@@ -96,12 +147,13 @@ func CreateTmuxSession(name string, directory string, forceAttach bool, forceRec
 	}
 
 	// Create additional windows
-	for i := 1; i < len(cfg.Windows); i++ {
-		window := cfg.Windows[i]
+	for i := 1; i < len(windows); i++ {
+		window := windows[i]
 		windowIndex := fmt.Sprintf("%d", i)
 
-		newWindowCmd := exec.Command("tmux", "new-window", "-t",
-			name+":"+windowIndex, "-n", window.Name, "-c", directory)
+		newWindowArgs := []string{"new-window", "-t", name + ":" + windowIndex, "-n", window.Name, "-c", directory}
+		newWindowArgs = append(newWindowArgs, envArgs(windowEnvs[i])...)
+		newWindowCmd := exec.Command("tmux", newWindowArgs...)
 		if err := newWindowCmd.Run(); err != nil {
 			return err
 		}
@@ -128,3 +180,78 @@ func CreateTmuxSession(name string, directory string, forceAttach bool, forceRec
 	attachCmd.Stderr = os.Stderr
 	return attachCmd.Run()
 }
+
+// filterWindows returns the windows whose if_exists, if_command and if_branch conditions all hold
+func filterWindows(windows []config.WindowConfig, directory string, branch string) []config.WindowConfig {
+	var enabled []config.WindowConfig
+	for _, window := range windows {
+		if window.IfExists != "" {
+			matches, _ := filepath.Glob(filepath.Join(directory, window.IfExists))
+			if len(matches) == 0 {
+				continue
+			}
+		}
+		if window.IfCommand != "" {
+			if _, err := exec.LookPath(window.IfCommand); err != nil {
+				continue
+			}
+		}
+		if window.IfBranch != "" {
+			if ok, _ := filepath.Match(window.IfBranch, branch); !ok {
+				continue
+			}
+		}
+		enabled = append(enabled, window)
+	}
+	return enabled
+}
+
+// expandWindowCommands returns a copy of windows with variables in their commands expanded
+func expandWindowCommands(windows []config.WindowConfig, name string, directory string, branch string) ([]config.WindowConfig, error) {
+	vars := config.Variables{
+		"repo":    filepath.Base(directory),
+		"path":    directory,
+		"session": name,
+	}
+	if branch != "" {
+		vars["branch"] = branch
+	}
+
+	expanded := make([]config.WindowConfig, len(windows))
+	for i, window := range windows {
+		command, err := config.ExpandVariables(window.Command, vars)
+		if err != nil {
+			return nil, fmt.Errorf("window %q: %w", window.Name, err)
+		}
+		expanded[i] = window
+		expanded[i].Command = command
+	}
+	return expanded, nil
+}
+
+// loadEnv reads dotenv files relative to directory and applies explicit values on top
+func loadEnv(directory string, files []string, values map[string]string) (map[string]string, error) {
+	env, err := config.LoadEnvFiles(directory, files)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		env[key] = value
+	}
+	return env, nil
+}
+
+// envArgs converts an environment into tmux -e flags, sorted for stable ordering
+func envArgs(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		args = append(args, "-e", key+"="+env[key])
+	}
+	return args
+}
@@ -0,0 +1,50 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Session describes a running tmux session
+type Session struct {
+	Name     string
+	Windows  int
+	Attached bool // whether any client is attached
+}
+
+// SessionName returns the name tmux gives a session requested as name: tmux replaces
+// '.' and ':' (which have a meaning in targets) with '_', and older versions reject them
+func SessionName(name string) string {
+	return strings.NewReplacer(".", "_", ":", "_").Replace(name)
+}
+
+// ListSessions returns the running tmux sessions keyed by name
+// An empty map is returned when no tmux server is running.
+func ListSessions() (map[string]Session, error) {
+	// The name goes last as it may contain spaces; tmux would print tabs as underscores
+	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_windows} #{session_attached} #{session_name}")
+	output, err := cmd.Output()
+	if err != nil {
+		// tmux exits non-zero when there is no server (and so no sessions) to list
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return map[string]Session{}, nil
+		}
+		return nil, fmt.Errorf("failed to list tmux sessions: %w", err)
+	}
+
+	sessions := make(map[string]Session)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		windows, _ := strconv.Atoi(fields[0])
+		attached, _ := strconv.Atoi(fields[1])
+		sessions[fields[2]] = Session{Name: fields[2], Windows: windows, Attached: attached > 0}
+	}
+	return sessions, nil
+}
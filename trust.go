@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	config "github.com/Haptic-Labs/tmux-sessionizer/config"
+	utils "github.com/Haptic-Labs/tmux-sessionizer/utils"
+)
+
+// confirmInTreeConfig asks the user to approve an untrusted in-tree config
+// Returns the re-resolved config if the user trusted it, otherwise the resolution unchanged
+func confirmInTreeConfig(resolution *config.Resolution, repoDir string) *config.Resolution {
+	inTree := resolution.InTree
+	if inTree == nil || inTree.Trusted || resolution.Source == config.SourceRepo {
+		return resolution
+	}
+
+	if !promptTrust(inTree) {
+		fmt.Printf("Ignoring untrusted %s\n", filepath.Base(inTree.Path))
+		return resolution
+	}
+
+	if err := inTree.Trust(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error trusting config: %v\n", err)
+		return resolution
+	}
+
+	return config.ResolveConfig(repoDir)
+}
+
+// promptTrust shows what changed in an in-tree config since it was last trusted and asks for approval
+func promptTrust(inTree *config.InTreeConfig) bool {
+	previous, ok := inTree.PreviouslyTrusted()
+	if ok {
+		fmt.Printf("%s has changed since you trusted it:\n\n", inTree.Path)
+	} else {
+		fmt.Printf("%s is not trusted. Its commands will run in your shell:\n\n", inTree.Path)
+	}
+
+	for _, line := range utils.LineDiff(string(previous), string(inTree.Data)) {
+		fmt.Println(line)
+	}
+
+	fmt.Print("\nTrust this config? [y/N] ")
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(strings.ToLower(input))
+
+	return input == "y" || input == "yes"
+}
+
+const trustUsage = "trust [--yes] [path]"
+
+// runTrust reviews and approves a repository's in-tree config
+func runTrust(args []string) error {
+	fs := newCommandFlagSet(trustUsage)
+	yes := fs.Bool("yes", false, "Trust without showing the diff prompt")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	repoDir, err := repoDirArg(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	inTree, err := config.ReadInTreeConfig(repoDir)
+	if err != nil {
+		return err
+	}
+	if inTree == nil {
+		return fmt.Errorf("no .tmux-sessionizer config found in %s", repoDir)
+	}
+	if inTree.Trusted {
+		fmt.Printf("%s is already trusted\n", inTree.Path)
+		return nil
+	}
+
+	if !*yes && !promptTrust(inTree) {
+		return fmt.Errorf("not trusted")
+	}
+
+	if err := inTree.Trust(); err != nil {
+		return err
+	}
+	fmt.Printf("Trusted %s\n", inTree.Path)
+	return nil
+}
+
+const untrustUsage = "untrust [path]"
+
+// runUntrust revokes trust for a repository's in-tree config
+func runUntrust(args []string) error {
+	fs := newCommandFlagSet(untrustUsage)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	repoDir, err := repoDirArg(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	if err := config.UntrustInTreeConfig(repoDir); err != nil {
+		return err
+	}
+	fmt.Printf("Removed trust for the in-tree config in %s\n", repoDir)
+	return nil
+}
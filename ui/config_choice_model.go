@@ -3,6 +3,7 @@ package ui
 import (
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -11,14 +12,16 @@ type ConfigChoiceModel struct {
 	Options  []string
 	Cursor   int
 	Selected int
+	Keys     KeyMap // Key bindings, from the user's ui.keymap
 }
 
 // InitializeConfigChoiceModel creates a new config choice model
 func InitializeConfigChoiceModel() ConfigChoiceModel {
 	return ConfigChoiceModel{
-		Options:  []string{"Global configuration", "Repo-level configuration"},
+		Options:  []string{"Global configuration", "Repo-level configuration", "Copy a repo configuration to another repo"},
 		Cursor:   0,
 		Selected: -1,
+		Keys:     keymap,
 	}
 }
 
@@ -31,19 +34,19 @@ func (m *ConfigChoiceModel) Init() tea.Cmd {
 func (m *ConfigChoiceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q", "esc":
+		switch {
+		case msg.String() == "ctrl+c" || key.Matches(msg, m.Keys.Quit, m.Keys.Cancel):
 			m.Selected = -1
 			return m, tea.Quit
-		case "up", "k":
+		case key.Matches(msg, m.Keys.Up):
 			if m.Cursor > 0 {
 				m.Cursor--
 			}
-		case "down", "j":
+		case key.Matches(msg, m.Keys.Down):
 			if m.Cursor < len(m.Options)-1 {
 				m.Cursor++
 			}
-		case "enter", " ":
+		case key.Matches(msg, m.Keys.Select):
 			m.Selected = m.Cursor
 			return m, tea.Quit
 		}
@@ -55,17 +58,17 @@ func (m *ConfigChoiceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m *ConfigChoiceModel) View() string {
 	var s strings.Builder
 
-	s.WriteString("Choose configuration type:\n\n")
+	s.WriteString(theme.Title.Render("Choose configuration type:") + "\n\n")
 
 	for i, option := range m.Options {
-		cursor := " "
+		row := option
 		if m.Cursor == i {
-			cursor = ">"
+			row = theme.Cursor.Render(row)
 		}
-		s.WriteString(cursor + " " + option + "\n")
+		s.WriteString(cursorMarker(m.Cursor == i) + " " + row + "\n")
 	}
 
-	s.WriteString("\nPress Enter to select, q to quit.\n")
+	s.WriteString("\n" + helpView(m.Keys.Up, m.Keys.Down, m.Keys.Select, m.Keys.Quit) + "\n")
 
 	return s.String()
 }
@@ -1,11 +1,15 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
 
 	config "github.com/Haptic-Labs/tmux-sessionizer/config"
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -13,57 +17,82 @@ import (
 type ConfigUIMode int
 
 const (
-	ModeList ConfigUIMode = iota // List all windows
-	ModeEdit                     // Edit a window
-	ModeAdd                      // Add new window
+	ModeList     ConfigUIMode = iota // List all windows
+	ModeEdit                         // Edit a window
+	ModeAdd                          // Add new window
+	ModeConflict                     // Config changed on disk; ask how to save
 )
 
 // ConfigModel represents the configuration UI state
 type ConfigModel struct {
-	Config       *config.Config
-	Mode         ConfigUIMode
-	Cursor       int
-	EditingIndex int
-	EditField    int // 0 for name, 1 for command
-	NameInput    string
-	CommandInput string
-	Message      string
-	Error        string
-	Saved        bool
-	RepoDir      string // If non-empty, saves to repo config instead of global
+	Config         *config.Config
+	Mode           ConfigUIMode
+	Cursor         int
+	EditingIndex   int
+	EditField      int // 0 for name, 1 for command
+	NameInput      textinput.Model
+	CommandInput   textinput.Model
+	Message        string
+	Error          string
+	Saved          bool
+	RepoDir        string          // If non-empty, saves to repo config instead of global
+	UndoDepth      int             // How many saved versions back the undo action has gone
+	CommandHistory []string        // Previously entered commands, oldest first
+	historyIndex   int             // Position in CommandHistory; len(CommandHistory) is the draft
+	historyDraft   string          // Command being typed before browsing history
+	Keys           KeyMap          // Key bindings, from the user's ui.keymap
+	Snapshot       config.Snapshot // On-disk version the config was loaded from
 }
 
 // InitializeConfigModel initializes the config UI model
-func InitializeConfigModel(cfg *config.Config) ConfigModel {
-	return ConfigModel{
+// snapshot must be taken before cfg was loaded, so that any write since is caught on save
+func InitializeConfigModel(cfg *config.Config, snapshot config.Snapshot) ConfigModel {
+	m := ConfigModel{
 		Config:       cfg,
 		Mode:         ModeList,
 		Cursor:       0,
 		EditingIndex: -1,
 		EditField:    0,
-		NameInput:    "",
-		CommandInput: "",
+		NameInput:    newTextInput(),
+		CommandInput: newTextInput(),
 		Message:      "",
 		Error:        "",
 		Saved:        false,
+		Keys:         keymap,
+		Snapshot:     snapshot,
 	}
+	m.Message = m.inheritWindows()
+	return m
 }
 
 // InitializeRepoConfigModel initializes the config UI model for a specific repo
-func InitializeRepoConfigModel(cfg *config.Config, repoDir string) ConfigModel {
-	return ConfigModel{
+// snapshot must be taken before cfg was loaded, as for InitializeConfigModel
+func InitializeRepoConfigModel(cfg *config.Config, repoDir string, snapshot config.Snapshot) ConfigModel {
+	m := ConfigModel{
 		Config:       cfg,
 		Mode:         ModeList,
 		Cursor:       0,
 		EditingIndex: -1,
 		EditField:    0,
-		NameInput:    "",
-		CommandInput: "",
+		NameInput:    newTextInput(),
+		CommandInput: newTextInput(),
 		Message:      "",
 		Error:        "",
 		Saved:        false,
 		RepoDir:      repoDir,
+		Keys:         keymap,
+		Snapshot:     snapshot,
 	}
+	m.Message = m.inheritWindows()
+	return m
+}
+
+// newTextInput creates an unfocused single-line input for the edit form
+func newTextInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = ""
+	input.Cursor.SetMode(cursor.CursorStatic)
+	return input
 }
 
 // Init is the bubbletea initialization function
@@ -80,6 +109,8 @@ func (m *ConfigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateList(msg)
 		case ModeEdit, ModeAdd:
 			return m.updateEditAdd(msg)
+		case ModeConflict:
+			return m.updateConflict(msg)
 		}
 	}
 	return m, nil
@@ -87,38 +118,34 @@ func (m *ConfigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // updateList handles key input in List mode
 func (m *ConfigModel) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c", "q":
+	switch {
+	case msg.String() == "ctrl+c" || key.Matches(msg, m.Keys.Quit):
 		// Quit without saving
 		return m, tea.Quit
-	case "up", "k":
+	case key.Matches(msg, m.Keys.Up):
 		if m.Cursor > 0 {
 			m.Cursor--
 		}
-	case "down", "j":
+	case key.Matches(msg, m.Keys.Down):
 		if m.Cursor < len(m.Config.Windows)-1 {
 			m.Cursor++
 		}
-	case "a":
+	case key.Matches(msg, m.Keys.Add):
 		// Add new window
 		m.Mode = ModeAdd
-		m.NameInput = ""
-		m.CommandInput = ""
-		m.EditField = 0
+		m.startEditing("", "")
 		m.Message = ""
 		m.Error = ""
-	case "e", "enter", " ":
+	case key.Matches(msg, m.Keys.Edit):
 		// Edit current window
 		if len(m.Config.Windows) > 0 && m.Cursor < len(m.Config.Windows) {
 			m.Mode = ModeEdit
 			m.EditingIndex = m.Cursor
-			m.NameInput = m.Config.Windows[m.Cursor].Name
-			m.CommandInput = m.Config.Windows[m.Cursor].Command
-			m.EditField = 0
+			m.startEditing(m.Config.Windows[m.Cursor].Name, m.Config.Windows[m.Cursor].Command)
 			m.Message = ""
 			m.Error = ""
 		}
-	case "d":
+	case key.Matches(msg, m.Keys.Delete):
 		// Delete current window
 		if len(m.Config.Windows) > 1 && m.Cursor < len(m.Config.Windows) {
 			// Remove the window at cursor position
@@ -131,102 +158,306 @@ func (m *ConfigModel) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		} else if len(m.Config.Windows) == 1 {
 			m.Error = "Cannot delete the last window"
 		}
-	case "ctrl+up", "ctrl+k":
+	case key.Matches(msg, m.Keys.MoveUp):
 		// Move window up
 		if m.Cursor > 0 && m.Cursor < len(m.Config.Windows) {
 			m.Config.Windows[m.Cursor], m.Config.Windows[m.Cursor-1] = m.Config.Windows[m.Cursor-1], m.Config.Windows[m.Cursor]
 			m.Cursor--
 			m.Message = "Window moved up"
 		}
-	case "ctrl+down", "ctrl+j":
+	case key.Matches(msg, m.Keys.MoveDown):
 		// Move window down
 		if m.Cursor >= 0 && m.Cursor < len(m.Config.Windows)-1 {
 			m.Config.Windows[m.Cursor], m.Config.Windows[m.Cursor+1] = m.Config.Windows[m.Cursor+1], m.Config.Windows[m.Cursor]
 			m.Cursor++
 			m.Message = "Window moved down"
 		}
-	case "s":
-		// Save and exit
-		var err error
-		if m.RepoDir != "" {
-			// Save to repo config
-			err = config.SaveRepoConfig(m.RepoDir, m.Config)
-		} else {
-			// Save to global config
-			err = config.SaveConfig(m.Config)
+	case key.Matches(msg, m.Keys.Undo):
+		// Load the previously saved version; repeat to go further back
+		path, err := m.configPath()
+		if err != nil {
+			m.Error = fmt.Sprintf("Error locating config: %v", err)
+			break
+		}
+		cfg, err := config.LoadConfigVersion(path, m.UndoDepth+1)
+		if err != nil {
+			m.Message = ""
+			m.Error = fmt.Sprintf("Nothing to undo: %v", err)
+			break
+		}
+		m.UndoDepth++
+		m.Config = cfg
+		m.Cursor = 0
+		m.Error = ""
+		m.Message = fmt.Sprintf("Loaded saved version %d (press %s to save it, %s to go further back)",
+			m.UndoDepth, m.Keys.Save.Help().Key, m.Keys.Undo.Help().Key)
+		if note := m.inheritWindows(); note != "" {
+			m.Message += "; " + note
+		}
+	case key.Matches(msg, m.Keys.Save):
+		// Save and exit, unless the file changed on disk since it was loaded
+		err := m.save(false)
+		if errors.Is(err, config.ErrConfigChanged) {
+			m.Mode = ModeConflict
+			m.Message = ""
+			m.Error = ""
+			return m, nil
 		}
-
 		if err != nil {
 			m.Error = fmt.Sprintf("Error saving config: %v", err)
 		} else {
-			m.Saved = true
-			if m.RepoDir != "" {
-				m.Message = "Repo configuration saved!"
-			} else {
-				m.Message = "Global configuration saved!"
-			}
 			return m, tea.Quit
 		}
 	}
 	return m, nil
 }
 
+// updateConflict handles key input after a save found the file changed on disk
+func (m *ConfigModel) updateConflict(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// ctrl+c always backs out, whatever the key map says
+	if msg.String() == "ctrl+c" {
+		m.Mode = ModeList
+		m.Message = "Save cancelled"
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.Keys.Overwrite):
+		// Overwrite the on-disk changes with ours
+		if err := m.save(true); err != nil {
+			m.Mode = ModeList
+			m.Error = fmt.Sprintf("Error saving config: %v", err)
+			return m, nil
+		}
+		return m, tea.Quit
+	case key.Matches(msg, m.Keys.Reload):
+		// Discard our changes and load what's on disk now
+		m.Mode = ModeList
+		if err := m.reload(); err != nil {
+			m.Error = fmt.Sprintf("Error reloading config: %v", err)
+			return m, nil
+		}
+		m.Error = ""
+		m.Message = "Reloaded the config from disk; your unsaved changes were discarded"
+		if note := m.inheritWindows(); note != "" {
+			m.Message += "; " + note
+		}
+	case key.Matches(msg, m.Keys.KeepEditing):
+		// Keep editing without saving
+		m.Mode = ModeList
+		m.Message = "Save cancelled"
+	}
+	return m, nil
+}
+
+// save writes the config; unless force is set it fails with config.ErrConfigChanged
+// if the file changed on disk since the model's snapshot was taken
+func (m *ConfigModel) save(force bool) error {
+	var err error
+	switch {
+	case m.RepoDir != "" && force:
+		err = config.SaveRepoConfig(m.RepoDir, m.Config)
+	case m.RepoDir != "":
+		err = config.SaveRepoConfigChecked(m.RepoDir, m.Config, m.Snapshot)
+	case force:
+		err = config.SaveConfig(m.Config)
+	default:
+		err = config.SaveConfigChecked(m.Config, m.Snapshot)
+	}
+	if err != nil {
+		return err
+	}
+
+	m.Saved = true
+	if m.RepoDir != "" {
+		m.Message = "Repo configuration saved!"
+	} else {
+		m.Message = "Global configuration saved!"
+	}
+	return nil
+}
+
+// reload replaces the edited config with the current on-disk version
+func (m *ConfigModel) reload() error {
+	var snapshot config.Snapshot
+	var cfg *config.Config
+	var err error
+	if m.RepoDir != "" {
+		if snapshot, err = config.SnapshotRepoConfig(m.RepoDir); err != nil {
+			return err
+		}
+		cfg, err = config.LoadRepoConfigForEdit(m.RepoDir)
+	} else {
+		if snapshot, err = config.SnapshotConfig(); err != nil {
+			return err
+		}
+		cfg, err = config.LoadConfigForEdit()
+	}
+	if err != nil {
+		return err
+	}
+
+	m.Config = cfg
+	m.Snapshot = snapshot
+	m.Cursor = 0
+	m.UndoDepth = 0
+	return nil
+}
+
+// inheritWindows copies the windows an include provides into a config that doesn't set
+// its own, so they are shown and edited rather than replaced by the first edit
+// It returns a note for the user when windows were copied.
+func (m *ConfigModel) inheritWindows() string {
+	path, err := m.configPath()
+	if err != nil {
+		m.Error = fmt.Sprintf("Error locating config: %v", err)
+		return ""
+	}
+	from, err := m.Config.InheritIncludedWindows(path, "")
+	if err != nil {
+		m.Error = fmt.Sprintf("Error reading includes: %v", err)
+		return ""
+	}
+	if from == "" {
+		return ""
+	}
+	return fmt.Sprintf("Showing %d windows inherited from %s; saving writes them into this config", len(m.Config.Windows), from)
+}
+
+// configPath returns the file this model saves to
+func (m *ConfigModel) configPath() (string, error) {
+	if m.RepoDir != "" {
+		return config.GetRepoConfigPath(m.RepoDir)
+	}
+	return config.GetConfigPath()
+}
+
+// startEditing fills the edit form and focuses the name field
+func (m *ConfigModel) startEditing(name string, command string) {
+	m.NameInput.SetValue(name)
+	m.NameInput.CursorEnd()
+	m.CommandInput.SetValue(command)
+	m.CommandInput.CursorEnd()
+	m.focusField(0)
+
+	m.CommandHistory = config.LoadCommandHistory()
+	m.historyIndex = len(m.CommandHistory)
+	m.historyDraft = ""
+}
+
+// focusField moves keyboard focus to the name (0) or command (1) field
+func (m *ConfigModel) focusField(field int) {
+	m.EditField = field
+	if field == 0 {
+		m.NameInput.Focus()
+		m.CommandInput.Blur()
+	} else {
+		m.NameInput.Blur()
+		m.CommandInput.Focus()
+	}
+}
+
+// browseHistory replaces the command with an older (delta < 0) or newer history entry
+// Browsing past the newest entry returns to the command being typed
+func (m *ConfigModel) browseHistory(delta int) {
+	index := min(max(m.historyIndex+delta, 0), len(m.CommandHistory))
+	if index == m.historyIndex {
+		return
+	}
+
+	if m.historyIndex == len(m.CommandHistory) {
+		m.historyDraft = m.CommandInput.Value()
+	}
+	m.historyIndex = index
+
+	if index == len(m.CommandHistory) {
+		m.CommandInput.SetValue(m.historyDraft)
+	} else {
+		m.CommandInput.SetValue(m.CommandHistory[index])
+	}
+	m.CommandInput.CursorEnd()
+}
+
 // updateEditAdd handles key input in Edit/Add mode
+// Keys the form doesn't use go to the focused text input; single-character
+// bindings are ignored here so those characters can be typed
 func (m *ConfigModel) updateEditAdd(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
+	keys := m.Keys.typingSafe()
+	switch {
+	case key.Matches(msg, keys.Cancel):
 		// Cancel and return to list
 		m.Mode = ModeList
 		m.EditingIndex = -1
 		m.Message = ""
 		m.Error = ""
-	case "tab":
+		return m, nil
+	case key.Matches(msg, keys.NextField):
 		// Switch between fields
-		m.EditField = (m.EditField + 1) % 2
-	case "enter":
+		m.focusField((m.EditField + 1) % 2)
+		return m, nil
+	case m.EditField == 1 && key.Matches(msg, keys.HistoryPrev):
+		// Browse previously used commands
+		m.browseHistory(-1)
+		return m, nil
+	case m.EditField == 1 && key.Matches(msg, keys.HistoryNext):
+		m.browseHistory(1)
+		return m, nil
+	case key.Matches(msg, keys.Select):
 		// Save the window
-		if m.NameInput == "" {
+		name := strings.TrimSpace(m.NameInput.Value())
+		command := m.CommandInput.Value()
+		if name == "" {
 			m.Error = "Window name cannot be empty"
 			return m, nil
 		}
 
 		if m.Mode == ModeEdit {
 			// Update existing window
-			m.Config.Windows[m.EditingIndex].Name = m.NameInput
-			m.Config.Windows[m.EditingIndex].Command = m.CommandInput
+			m.Config.Windows[m.EditingIndex].Name = name
+			m.Config.Windows[m.EditingIndex].Command = command
 			m.Message = "Window updated"
 		} else if m.Mode == ModeAdd {
 			// Add new window
 			newWindow := config.WindowConfig{
-				Name:    m.NameInput,
-				Command: m.CommandInput,
+				Name:    name,
+				Command: command,
 			}
 			m.Config.Windows = append(m.Config.Windows, newWindow)
 			m.Cursor = len(m.Config.Windows) - 1
 			m.Message = "Window added"
 		}
 
+		// Remember the command for later edits; history is a convenience, so failures are ignored
+		config.AddCommandHistory(command)
+
 		// Return to list mode
 		m.Mode = ModeList
 		m.EditingIndex = -1
-	case "backspace":
-		// Delete character from current field
-		if m.EditField == 0 && len(m.NameInput) > 0 {
-			m.NameInput = m.NameInput[:len(m.NameInput)-1]
-		} else if m.EditField == 1 && len(m.CommandInput) > 0 {
-			m.CommandInput = m.CommandInput[:len(m.CommandInput)-1]
-		}
-	default:
-		// Add character to current field
-		if len(msg.String()) == 1 {
-			if m.EditField == 0 {
-				m.NameInput += msg.String()
-			} else if m.EditField == 1 {
-				m.CommandInput += msg.String()
-			}
-		}
+		m.Error = ""
+		return m, nil
 	}
-	return m, nil
+
+	var cmd tea.Cmd
+	if m.EditField == 0 {
+		m.NameInput, cmd = m.NameInput.Update(msg)
+	} else {
+		m.CommandInput, cmd = m.CommandInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// formHelpView renders the help footer for the edit form
+func (m *ConfigModel) formHelpView() string {
+	keys := m.Keys.typingSafe()
+	bindings := []key.Binding{keys.NextField}
+	if m.EditField == 1 {
+		bindings = append(bindings, keys.HistoryPrev, keys.HistoryNext)
+	}
+	save := keys.Select
+	save.SetHelp(save.Help().Key, "save window")
+	cancel := keys.Cancel
+	cancel.SetHelp(cancel.Help().Key, "cancel")
+	return helpView(append(bindings, save, cancel)...)
 }
 
 // View renders the UI
@@ -236,87 +467,70 @@ func (m *ConfigModel) View() string {
 	switch m.Mode {
 	case ModeList:
 		if m.RepoDir != "" {
-			s.WriteString(fmt.Sprintf("Configure repo-level windows (%s)\n", filepath.Base(m.RepoDir)))
+			s.WriteString(theme.Title.Render(fmt.Sprintf("Configure repo-level windows (%s)", filepath.Base(m.RepoDir))) + "\n")
 		} else {
-			s.WriteString("Configure global tmux-sessionizer windows\n")
+			s.WriteString(theme.Title.Render("Configure global tmux-sessionizer windows") + "\n")
 		}
-		s.WriteString("(Use arrows to navigate, Enter to edit, 'a' to add, 'd' to delete, 's' to save)\n\n")
+		s.WriteString("\n")
 
 		// Display all windows
 		for i, window := range m.Config.Windows {
-			cursor := " "
-			if m.Cursor == i {
-				cursor = ">"
-			}
-
 			commandDisplay := "<none>"
 			if window.Command != "" {
 				commandDisplay = window.Command
 			}
 
-			s.WriteString(fmt.Sprintf("%s Window %d: %s (command: %s)\n", cursor, i, window.Name, commandDisplay))
+			row := fmt.Sprintf("Window %d: %s (command: %s)", i, window.Name, commandDisplay)
+			if m.Cursor == i {
+				row = theme.Cursor.Render(row)
+			}
+			s.WriteString(fmt.Sprintf("%s %s\n", cursorMarker(m.Cursor == i), row))
 		}
 
 		s.WriteString("\n")
 
 		// Show key bindings
-		s.WriteString("[a] Add  [e/Enter] Edit  [d] Delete  [Ctrl+k/j or Ctrl+↑/↓] Move  [s] Save & Exit  [q] Cancel\n")
+		s.WriteString(helpView(m.Keys.Up, m.Keys.Down, m.Keys.Add, m.Keys.Edit, m.Keys.Delete, m.Keys.MoveUp, m.Keys.MoveDown, m.Keys.Undo, m.Keys.Save, m.Keys.Quit) + "\n")
 
 		// Show message or error
 		if m.Message != "" {
-			s.WriteString(fmt.Sprintf("\n%s\n", m.Message))
+			s.WriteString("\n" + theme.Configured.Render(m.Message) + "\n")
 		}
 		if m.Error != "" {
-			s.WriteString(fmt.Sprintf("\nError: %s\n", m.Error))
+			s.WriteString("\n" + theme.Error.Render("Error: "+m.Error) + "\n")
 		}
 
 	case ModeEdit:
-		s.WriteString(fmt.Sprintf("Editing Window %d\n", m.EditingIndex))
-		s.WriteString("(Tab to switch fields, Enter to save, Esc to cancel)\n\n")
-
-		// Name field
-		nameCursor := " "
-		if m.EditField == 0 {
-			nameCursor = "▊"
-		}
-		s.WriteString(fmt.Sprintf("Name:    %s%s\n", m.NameInput, nameCursor))
+		s.WriteString(theme.Title.Render(fmt.Sprintf("Editing Window %d", m.EditingIndex)) + "\n")
+		s.WriteString("\n")
 
-		// Command field
-		commandCursor := " "
-		if m.EditField == 1 {
-			commandCursor = "▊"
-		}
-		s.WriteString(fmt.Sprintf("Command: %s%s\n", m.CommandInput, commandCursor))
+		s.WriteString(fmt.Sprintf("Name:    %s\n", m.NameInput.View()))
+		s.WriteString(fmt.Sprintf("Command: %s\n", m.CommandInput.View()))
 
-		s.WriteString("\n[Tab] Switch field  [Enter] Save  [Esc] Cancel\n")
+		s.WriteString("\n" + m.formHelpView() + "\n")
 
 		if m.Error != "" {
-			s.WriteString(fmt.Sprintf("\nError: %s\n", m.Error))
+			s.WriteString("\n" + theme.Error.Render("Error: "+m.Error) + "\n")
 		}
 
 	case ModeAdd:
-		s.WriteString("Add New Window\n")
-		s.WriteString("(Tab to switch fields, Enter to save, Esc to cancel)\n\n")
-
-		// Name field
-		nameCursor := " "
-		if m.EditField == 0 {
-			nameCursor = "▊"
-		}
-		s.WriteString(fmt.Sprintf("Name:    %s%s\n", m.NameInput, nameCursor))
+		s.WriteString(theme.Title.Render("Add New Window") + "\n")
+		s.WriteString("\n")
 
-		// Command field
-		commandCursor := " "
-		if m.EditField == 1 {
-			commandCursor = "▊"
-		}
-		s.WriteString(fmt.Sprintf("Command: %s%s\n", m.CommandInput, commandCursor))
+		s.WriteString(fmt.Sprintf("Name:    %s\n", m.NameInput.View()))
+		s.WriteString(fmt.Sprintf("Command: %s\n", m.CommandInput.View()))
 
-		s.WriteString("\n[Tab] Switch field  [Enter] Save  [Esc] Cancel\n")
+		s.WriteString("\n" + m.formHelpView() + "\n")
 
 		if m.Error != "" {
-			s.WriteString(fmt.Sprintf("\nError: %s\n", m.Error))
+			s.WriteString("\n" + theme.Error.Render("Error: "+m.Error) + "\n")
 		}
+
+	case ModeConflict:
+		path, _ := m.configPath()
+		s.WriteString(theme.Error.Render("The config file changed on disk since it was opened") + "\n")
+		s.WriteString(theme.Muted.Render(fmt.Sprintf("(%s)", path)) + "\n\n")
+		s.WriteString(helpView(m.Keys.Overwrite, m.Keys.Reload, m.Keys.KeepEditing) + "\n")
 	}
 
 	return s.String()
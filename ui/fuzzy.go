@@ -0,0 +1,283 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Scoring constants, modelled on fzf's
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	bonusStart          = 10 // first character of the text
+	bonusBoundary       = 8  // after a word separator such as - _ . or space
+	bonusPathSeparator  = 9  // after a /
+	bonusCamelCase      = 7  // lower->Upper or letter->digit transitions
+	bonusNonWord        = 8  // matching a separator itself
+	bonusConsecutive    = 4  // minimum bonus for a character directly following the previous match
+	bonusFirstCharMulti = 2  // boundary bonuses count double for the first query character
+	bonusBasename       = 2  // each match within the last path segment
+)
+
+// noScore marks impossible DP states
+const noScore = -1 << 30
+
+// charClass groups characters for boundary bonuses
+type charClass int
+
+const (
+	classNonWord charClass = iota
+	classPathSeparator
+	classLower
+	classUpper
+	classDigit
+	classLetter // letters without case
+)
+
+// classOf returns the class of r
+func classOf(r rune) charClass {
+	switch {
+	case r == '/' || r == '\\':
+		return classPathSeparator
+	case unicode.IsLower(r):
+		return classLower
+	case unicode.IsUpper(r):
+		return classUpper
+	case unicode.IsDigit(r):
+		return classDigit
+	case unicode.IsLetter(r):
+		return classLetter
+	}
+	return classNonWord
+}
+
+// positionBonus scores matching the character of class current that follows one of class prev
+func positionBonus(prev, current charClass) int {
+	if current == classNonWord || current == classPathSeparator {
+		return bonusNonWord
+	}
+	switch {
+	case prev == classPathSeparator:
+		return bonusPathSeparator
+	case prev == classNonWord:
+		return bonusBoundary
+	case prev == classLower && current == classUpper:
+		return bonusCamelCase
+	case prev != classDigit && current == classDigit:
+		return bonusCamelCase
+	}
+	return 0
+}
+
+// FuzzyMatch reports whether every character of query appears in text, in order, and
+// scores the best such alignment fzf-style: matches at word boundaries, path separators
+// or camelCase humps score higher, a consecutive run keeps the bonus of the character
+// that started it, and gaps cost points.
+// Matching is case-insensitive unless query contains an uppercase letter.
+// positions are the rune indexes of the matched characters in text.
+func FuzzyMatch(query, text string) (score int, positions []int, ok bool) {
+	if query == "" {
+		return 0, nil, true
+	}
+
+	caseSensitive := strings.ToLower(query) != query
+	pattern := []rune(query)
+	runes := []rune(text)
+	n, m := len(runes), len(pattern)
+	if m > n {
+		return 0, nil, false
+	}
+
+	fold := func(r rune) rune {
+		if caseSensitive {
+			return r
+		}
+		return unicode.ToLower(r)
+	}
+
+	// Per-position bonuses
+	bonus := make([]int, n)
+	basenameStart := 0
+	prev := classNonWord
+	for j, r := range runes {
+		current := classOf(r)
+		if j == 0 {
+			bonus[j] = bonusStart
+		} else {
+			bonus[j] = positionBonus(prev, current)
+		}
+		if current == classPathSeparator {
+			basenameStart = j + 1
+		}
+		prev = current
+	}
+
+	// matched[i][j]: best score with pattern[i] matched at runes[j]
+	// gapped[i][j]: best score with pattern[i] matched before j and runes up to j unmatched
+	matched := make([][]int, m)
+	gapped := make([][]int, m)
+	runBonus := make([][]int, m)         // bonus of the character starting the run ending at matched[i][j]
+	fromConsecutive := make([][]bool, m) // matched[i][j] continued matched[i-1][j-1]
+	gapFromMatch := make([][]bool, m)    // gapped[i][j] opened right after matched[i][j-1]
+	for i := range pattern {
+		matched[i] = make([]int, n)
+		gapped[i] = make([]int, n)
+		runBonus[i] = make([]int, n)
+		fromConsecutive[i] = make([]bool, n)
+		gapFromMatch[i] = make([]bool, n)
+
+		for j := range runes {
+			matched[i][j] = noScore
+			if fold(runes[j]) == fold(pattern[i]) {
+				charScore := scoreMatch
+				if j >= basenameStart {
+					charScore += bonusBasename
+				}
+
+				if i == 0 {
+					matched[i][j] = charScore + bonus[j]*bonusFirstCharMulti
+					runBonus[i][j] = bonus[j]
+				} else if j > 0 {
+					// A run carries the bonus of its first character, so "web" in
+					// "web-server" beats three separate boundary matches; a stronger
+					// boundary inside the run starts over with its own bonus
+					consecutive, consecutiveRun := noScore, 0
+					if matched[i-1][j-1] != noScore {
+						consecutiveRun = runBonus[i-1][j-1]
+						if bonus[j] >= bonusBoundary && bonus[j] > consecutiveRun {
+							consecutiveRun = bonus[j]
+						}
+						consecutive = matched[i-1][j-1] + charScore + max(bonus[j], consecutiveRun, bonusConsecutive)
+					}
+					gap := gapped[i-1][j-1]
+					if gap != noScore {
+						gap += charScore + bonus[j]
+					}
+					if consecutive != noScore && consecutive >= gap {
+						matched[i][j] = consecutive
+						runBonus[i][j] = consecutiveRun
+						fromConsecutive[i][j] = true
+					} else if gap != noScore {
+						matched[i][j] = gap
+						runBonus[i][j] = bonus[j]
+					}
+				}
+			}
+
+			gapped[i][j] = noScore
+			if j > 0 {
+				open, extend := noScore, noScore
+				if matched[i][j-1] != noScore {
+					open = matched[i][j-1] + scoreGapStart
+				}
+				if gapped[i][j-1] != noScore {
+					extend = gapped[i][j-1] + scoreGapExtension
+				}
+				if open != noScore && open >= extend {
+					gapped[i][j] = open
+					gapFromMatch[i][j] = true
+				} else {
+					gapped[i][j] = extend
+				}
+			}
+		}
+	}
+
+	// Best end position for the last pattern character
+	end := -1
+	for j := m - 1; j < n; j++ {
+		if matched[m-1][j] != noScore && (end == -1 || matched[m-1][j] > matched[m-1][end]) {
+			end = j
+		}
+	}
+	if end == -1 {
+		return 0, nil, false
+	}
+	score = matched[m-1][end]
+
+	// Walk back through the recorded choices to recover the matched positions
+	positions = make([]int, m)
+	j := end
+	for i := m - 1; i >= 0; i-- {
+		positions[i] = j
+		if i == 0 {
+			break
+		}
+		if fromConsecutive[i][j] {
+			j--
+			continue
+		}
+		j--
+		for !gapFromMatch[i-1][j] {
+			j--
+		}
+		j--
+	}
+
+	return score, positions, true
+}
+
+// fuzzyResult is one option that matched a query
+type fuzzyResult struct {
+	Option    string
+	Positions []int
+	Score     int
+}
+
+// highlightMatches renders text with the runes at positions in match style and the
+// rest in base style, styling runs of characters together
+func highlightMatches(text string, positions []int, match lipgloss.Style, base lipgloss.Style) string {
+	matched := make(map[int]bool, len(positions))
+	for _, position := range positions {
+		matched[position] = true
+	}
+
+	var b strings.Builder
+	var run []rune
+	runMatched := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runMatched {
+			b.WriteString(match.Render(string(run)))
+		} else {
+			b.WriteString(base.Render(string(run)))
+		}
+		run = run[:0]
+	}
+
+	for i, r := range []rune(text) {
+		if matched[i] != runMatched {
+			flush()
+			runMatched = matched[i]
+		}
+		run = append(run, r)
+	}
+	flush()
+	return b.String()
+}
+
+// fuzzyFilter returns the options matching query, best first
+// Ties go to the shorter option, then to the original order.
+func fuzzyFilter(query string, options []string) []fuzzyResult {
+	var results []fuzzyResult
+	for _, option := range options {
+		if score, positions, ok := FuzzyMatch(query, option); ok {
+			results = append(results, fuzzyResult{Option: option, Positions: positions, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return len(results[a].Option) < len(results[b].Option)
+	})
+	return results
+}
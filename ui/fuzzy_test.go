@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestFuzzyFilterRanking checks that a consecutive run starting at a boundary beats the
+// same characters matched at separate boundaries
+func TestFuzzyFilterRanking(t *testing.T) {
+	tests := []struct {
+		query   string
+		options []string
+		want    []string
+	}{
+		{"web", []string{"w-e-b-tools", "web-server"}, []string{"web-server", "w-e-b-tools"}},
+		{"api", []string{"a-p-i-long", "my-api"}, []string{"my-api", "a-p-i-long"}},
+		{"ui", []string{"utils-infra", "my-ui"}, []string{"my-ui", "utils-infra"}},
+		{"tsz", []string{"code/tools", "code/tmux-sessionizer"}, []string{"code/tmux-sessionizer"}},
+		{"api", []string{"code/api/web", "code/web/api"}, []string{"code/web/api", "code/api/web"}},
+	}
+
+	for _, test := range tests {
+		var got []string
+		for _, result := range fuzzyFilter(test.query, test.options) {
+			got = append(got, result.Option)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("fuzzyFilter(%q, %q) = %q, want %q", test.query, test.options, got, test.want)
+		}
+	}
+}
+
+// TestFuzzyMatchPositions checks which characters FuzzyMatch reports as matched
+func TestFuzzyMatchPositions(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+		want  []int
+		ok    bool
+	}{
+		{"web", "web-server", []int{0, 1, 2}, true},
+		{"web", "w-e-b-tools", []int{0, 2, 4}, true},
+		{"api", "my-api", []int{3, 4, 5}, true},
+		{"ui", "utils-infra", []int{0, 6}, true},
+		{"tsz", "tmux-sessionizer", []int{0, 5, 13}, true},
+		{"fb", "FooBar", []int{0, 3}, true},
+		{"FB", "foobar", nil, false},
+		{"xyz", "web-server", nil, false},
+	}
+
+	for _, test := range tests {
+		_, positions, ok := FuzzyMatch(test.query, test.text)
+		if ok != test.ok || !reflect.DeepEqual(positions, test.want) {
+			t.Errorf("FuzzyMatch(%q, %q) = %v, %v, want %v, %v", test.query, test.text, positions, ok, test.want, test.ok)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	config "github.com/Haptic-Labs/tmux-sessionizer/config"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap holds the key bindings for every named action, shared by all models
+// Field names correspond to config.KeymapActions (e.g. MoveUp is "move-up").
+type KeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+	Select   key.Binding
+	Quit     key.Binding
+	Cancel   key.Binding
+
+	// Picker search
+	Search      key.Binding
+	ClearSearch key.Binding
+
+	// Config editor list
+	Add      key.Binding
+	Edit     key.Binding
+	Delete   key.Binding
+	MoveUp   key.Binding
+	MoveDown key.Binding
+	Undo     key.Binding
+	Save     key.Binding
+
+	// Config editor form
+	NextField   key.Binding
+	HistoryPrev key.Binding
+	HistoryNext key.Binding
+
+	// Config editor save conflict
+	Overwrite   key.Binding
+	Reload      key.Binding
+	KeepEditing key.Binding
+}
+
+// DefaultKeyMap returns the built-in bindings
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up:          newBinding("up", []string{"up", "ctrl+p", "k"}),
+		Down:        newBinding("down", []string{"down", "ctrl+n", "j"}),
+		PageUp:      newBinding("page up", []string{"pgup"}),
+		PageDown:    newBinding("page down", []string{"pgdown"}),
+		Top:         newBinding("top", []string{"home", "g"}),
+		Bottom:      newBinding("bottom", []string{"end", "G"}),
+		Select:      newBinding("select", []string{"enter", " "}),
+		Quit:        newBinding("quit", []string{"q", "ctrl+c"}),
+		Cancel:      newBinding("back", []string{"esc"}),
+		Search:      newBinding("search", []string{"i", "/"}),
+		ClearSearch: newBinding("clear", []string{"ctrl+u"}),
+		Add:         newBinding("add", []string{"a"}),
+		Edit:        newBinding("edit", []string{"e", "enter", " "}),
+		Delete:      newBinding("delete", []string{"d"}),
+		MoveUp:      newBinding("move up", []string{"ctrl+up", "ctrl+k"}),
+		MoveDown:    newBinding("move down", []string{"ctrl+down", "ctrl+j"}),
+		Undo:        newBinding("undo", []string{"u"}),
+		Save:        newBinding("save & exit", []string{"s"}),
+		NextField:   newBinding("switch field", []string{"tab", "shift+tab"}),
+		HistoryPrev: newBinding("older command", []string{"up"}),
+		HistoryNext: newBinding("newer command", []string{"down"}),
+		Overwrite:   newBinding("overwrite with your changes", []string{"o"}),
+		Reload:      newBinding("reload from disk (discard your changes)", []string{"r"}),
+		KeepEditing: newBinding("cancel", []string{"c", "esc"}),
+	}
+}
+
+// bindings maps the action names in config.KeymapActions to the KeyMap's fields, which
+// are named after them ("move-up" is MoveUp)
+func (k *KeyMap) bindings() map[string]*key.Binding {
+	fields := reflect.ValueOf(k).Elem()
+	bindings := make(map[string]*key.Binding, len(config.KeymapActions))
+	for _, action := range config.KeymapActions {
+		if field := fields.FieldByName(actionFieldName(action)); field.IsValid() {
+			bindings[action] = field.Addr().Interface().(*key.Binding)
+		}
+	}
+	return bindings
+}
+
+// actionFieldName returns the KeyMap field name for an action name, e.g. "MoveUp" for "move-up"
+func actionFieldName(action string) string {
+	var name strings.Builder
+	for _, word := range strings.Split(action, "-") {
+		if word != "" {
+			name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return name.String()
+}
+
+// withOverrides returns a copy of k with the keys of the named actions replaced
+// Unknown action names are ignored; config validation reports them.
+func (k KeyMap) withOverrides(overrides map[string][]string) KeyMap {
+	bindings := k.bindings()
+	for action, keys := range overrides {
+		if binding, ok := bindings[action]; ok && len(keys) > 0 {
+			*binding = newBinding(binding.Help().Desc, keys)
+		}
+	}
+	return k
+}
+
+// newBinding creates a binding whose help lists its keys
+func newBinding(description string, keys []string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keysLabel(keys), description))
+}
+
+// keysLabel renders keys for the help footer, e.g. "↑/k"
+func keysLabel(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		switch k {
+		case "up":
+			labels[i] = "↑"
+		case "down":
+			labels[i] = "↓"
+		case "ctrl+up":
+			labels[i] = "ctrl+↑"
+		case "ctrl+down":
+			labels[i] = "ctrl+↓"
+		case " ":
+			labels[i] = "space"
+		default:
+			labels[i] = k
+		}
+	}
+	return strings.Join(labels, "/")
+}
+
+// typingSafe returns binding without its single-character keys, so that while text
+// is being typed those characters are inserted instead of triggering the action
+func typingSafe(binding key.Binding) key.Binding {
+	var keys []string
+	for _, k := range binding.Keys() {
+		if utf8.RuneCountInString(k) != 1 {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		// An empty key list would leave the binding matching nothing but still shown
+		return key.NewBinding(key.WithDisabled())
+	}
+	return newBinding(binding.Help().Desc, keys)
+}
+
+// typingSafe returns a copy of k in which every binding is typing-safe (see typingSafe)
+func (k KeyMap) typingSafe() KeyMap {
+	for _, binding := range k.bindings() {
+		*binding = typingSafe(*binding)
+	}
+	return k
+}
+
+// keymap is the key map models are initialized with; see ApplySettings
+var keymap = DefaultKeyMap()
+
+// helpView renders a one-line help footer for the given bindings
+func helpView(bindings ...key.Binding) string {
+	h := help.New()
+	h.Styles = theme.Help
+	return h.ShortHelpView(bindings)
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	config "github.com/Haptic-Labs/tmux-sessionizer/config"
+)
+
+// TestKeyMapActionsInSync checks that config.KeymapActions and the KeyMap fields name
+// the same actions, so every binding can be remapped and every action name binds something
+func TestKeyMapActionsInSync(t *testing.T) {
+	keys := DefaultKeyMap()
+	bindings := keys.bindings()
+
+	for _, action := range config.KeymapActions {
+		if _, ok := bindings[action]; !ok {
+			t.Errorf("action %q has no KeyMap field %s", action, actionFieldName(action))
+		}
+	}
+
+	fields := make(map[string]bool, len(config.KeymapActions))
+	for _, action := range config.KeymapActions {
+		fields[actionFieldName(action)] = true
+	}
+	keyMapType := reflect.TypeOf(keys)
+	for i := 0; i < keyMapType.NumField(); i++ {
+		if name := keyMapType.Field(i).Name; !fields[name] {
+			t.Errorf("KeyMap field %s has no action in config.KeymapActions", name)
+		}
+	}
+}
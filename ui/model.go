@@ -2,23 +2,87 @@ package ui
 
 import (
 	"fmt"
-	"strings"
+	"sort"
 
 	config "github.com/Haptic-Labs/tmux-sessionizer/config"
+	tmux "github.com/Haptic-Labs/tmux-sessionizer/tmux"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // BubbleteaModel represents the bubbletea UI state
 type BubbleteaModel struct {
-	Options            []string
-	FilteredOptions    []string
-	Cursor             int
-	Selected           int
-	DirMap             map[string]string
-	SearchQuery        string
-	ShowSearch         bool
-	ShowConfigIndicator bool          // Whether to show [configured] indicators
-	ConfiguredRepos    map[string]bool // Cache of which repos have configs
+	Options             []string
+	FilteredOptions     []string
+	MatchPositions      [][]int // Matched rune positions for each filtered option, for highlighting
+	Cursor              int
+	Selected            int
+	DirMap              map[string]string
+	SearchQuery         string
+	ShowSearch          bool
+	ShowConfigIndicator bool                    // Whether to show [configured] indicators
+	ConfiguredRepos     map[string]bool         // Cache of which repos have configs
+	Title               string                  // Heading shown above the list; defaults to "Select a repository:"
+	Height              int                     // Terminal height from the last tea.WindowSizeMsg; 0 renders every row
+	Offset              int                     // Index of the first visible row in FilteredOptions
+	Width               int                     // Terminal width from the last tea.WindowSizeMsg
+	ShowPreview         bool                    // Whether to show the preview pane for the highlighted repo
+	Previews            map[string]*RepoPreview // Loaded previews keyed by repo path
+	PickerMode          string                  // config.PickerModeInsert or config.PickerModeVim
+	Keys                KeyMap                  // Key bindings, from the user's ui.keymap
+	Normal              bool                    // In vim mode, whether keys navigate instead of typing
+	Sessions            map[string]tmux.Session // Running tmux sessions by name, marked in the list
+	previewsLoading     map[string]bool
+}
+
+// pickerChromeLines is how many lines the view uses besides list rows and the help footer:
+// title, search, blank, two scroll indicators, blank
+const pickerChromeLines = 6
+
+// visibleRows returns how many options fit on screen, or 0 if all of them are shown
+func (m *BubbleteaModel) visibleRows() int {
+	if m.Height == 0 {
+		return 0
+	}
+	rows := m.Height - pickerChromeLines - lipgloss.Height(m.footer())
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// scrollToCursor adjusts the scroll offset so the cursor row is visible
+func (m *BubbleteaModel) scrollToCursor() {
+	rows := m.visibleRows()
+	if rows == 0 {
+		m.Offset = 0
+		return
+	}
+	if m.Cursor < m.Offset {
+		m.Offset = m.Cursor
+	}
+	if m.Cursor >= m.Offset+rows {
+		m.Offset = m.Cursor - rows + 1
+	}
+	// Don't leave empty rows at the bottom after the list shrinks or the window grows
+	if maxOffset := len(m.FilteredOptions) - rows; m.Offset > maxOffset {
+		m.Offset = max(maxOffset, 0)
+	}
+}
+
+// moveCursor moves the cursor by delta rows, clamped to the filtered list
+func (m *BubbleteaModel) moveCursor(delta int) {
+	m.Cursor = min(max(m.Cursor+delta, 0), max(len(m.FilteredOptions)-1, 0))
+	m.scrollToCursor()
+}
+
+// pageSize is how far page up/down moves the cursor
+func (m *BubbleteaModel) pageSize() int {
+	if rows := m.visibleRows(); rows > 0 {
+		return rows
+	}
+	return 10
 }
 
 // Init is the bubbletea initialization function
@@ -27,23 +91,25 @@ func (m *BubbleteaModel) Init() tea.Cmd {
 	m.FilteredOptions = m.Options
 	// Show search if we have more than 3 options
 	m.ShowSearch = len(m.Options) > 3
+	// Vim mode starts out navigating
+	m.Normal = m.PickerMode == config.PickerModeVim
 	return nil
 }
 
-// FilterOptions filters the options based on search query
+// FilterOptions fuzzy-filters the options by the search query, best matches first
 func (m *BubbleteaModel) FilterOptions() {
 	if m.SearchQuery == "" {
 		m.FilteredOptions = m.Options
+		m.MatchPositions = nil
 		return
 	}
 
-	m.FilteredOptions = []string{}
-	lowerQuery := strings.ToLower(m.SearchQuery)
-
-	for _, opt := range m.Options {
-		if strings.Contains(strings.ToLower(opt), lowerQuery) {
-			m.FilteredOptions = append(m.FilteredOptions, opt)
-		}
+	results := fuzzyFilter(m.SearchQuery, m.Options)
+	m.FilteredOptions = make([]string, len(results))
+	m.MatchPositions = make([][]int, len(results))
+	for i, result := range results {
+		m.FilteredOptions[i] = result.Option
+		m.MatchPositions[i] = result.Positions
 	}
 
 	// Reset cursor if it's out of bounds
@@ -55,92 +121,333 @@ func (m *BubbleteaModel) FilterOptions() {
 // Update is the bubbletea update function that handles messages
 func (m *BubbleteaModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+		m.scrollToCursor()
+	case previewLoadedMsg:
+		delete(m.previewsLoading, msg.Path)
+		m.Previews[msg.Path] = msg.Preview
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		// ctrl+c always quits, whatever the key map says
+		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
-		case "up", "k":
-			if m.Cursor > 0 {
-				m.Cursor--
-			}
-		case "down", "j":
-			if m.Cursor < len(m.FilteredOptions)-1 {
-				m.Cursor++
+		}
+
+		keys := m.activeKeys()
+		switch {
+		case key.Matches(msg, keys.Up):
+			m.moveCursor(-1)
+		case key.Matches(msg, keys.Down):
+			m.moveCursor(1)
+		case key.Matches(msg, keys.PageUp):
+			m.moveCursor(-m.pageSize())
+		case key.Matches(msg, keys.PageDown):
+			m.moveCursor(m.pageSize())
+		case key.Matches(msg, keys.Top):
+			m.moveCursor(-len(m.FilteredOptions))
+		case key.Matches(msg, keys.Bottom):
+			m.moveCursor(len(m.FilteredOptions))
+		case key.Matches(msg, keys.Select):
+			return m, m.selectCursor()
+		case key.Matches(msg, keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, keys.Search) && m.navigating():
+			if m.ShowSearch {
+				m.Normal = false
 			}
-		case "enter", " ":
-			if len(m.FilteredOptions) > 0 {
-				// Find the original option index that corresponds to the filtered selection
-				selectedOption := m.FilteredOptions[m.Cursor]
-				for i, opt := range m.Options {
-					if opt == selectedOption {
-						m.Selected = i
-						break
-					}
-				}
+		case key.Matches(msg, keys.ClearSearch) && m.ShowSearch:
+			m.setQuery("")
+		case key.Matches(msg, keys.Cancel):
+			switch {
+			case m.navigating():
+				return m, tea.Quit
+			case m.PickerMode == config.PickerModeVim:
+				m.Normal = true
+			case m.SearchQuery != "":
+				m.setQuery("")
+			default:
 				return m, tea.Quit
-			}
-		case "backspace":
-			if m.ShowSearch && len(m.SearchQuery) > 0 {
-				m.SearchQuery = m.SearchQuery[:len(m.SearchQuery)-1]
-				m.FilterOptions()
 			}
 		default:
-			if m.ShowSearch {
-				// Check if it's a printable character
-				if len(msg.String()) == 1 {
-					m.SearchQuery += msg.String()
-					m.FilterOptions()
-					// Reset cursor position when search changes
-					if len(m.FilteredOptions) > 0 {
-						m.Cursor = 0
-					}
-				}
+			if !m.navigating() {
+				m.updateInsert(msg)
 			}
 		}
 	}
-	return m, nil
+	return m, m.requestPreview()
+}
+
+// navigating reports whether keys navigate the list rather than edit the search query
+// Without a search box there is nothing to type into, so keys always navigate.
+func (m *BubbleteaModel) navigating() bool {
+	return !m.ShowSearch || m.Normal
+}
+
+// activeKeys returns the bindings for the current mode
+// While typing, single-character bindings are dropped so those characters can be searched for.
+func (m *BubbleteaModel) activeKeys() KeyMap {
+	if m.navigating() {
+		return m.Keys
+	}
+	return m.Keys.typingSafe()
+}
+
+// updateInsert edits the search query with keys that aren't bound to an action
+func (m *BubbleteaModel) updateInsert(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyBackspace:
+		if runes := []rune(m.SearchQuery); len(runes) > 0 {
+			m.setQuery(string(runes[:len(runes)-1]))
+		}
+	case tea.KeySpace:
+		m.setQuery(m.SearchQuery + " ")
+	case tea.KeyRunes:
+		// May hold several characters when text is pasted
+		m.setQuery(m.SearchQuery + string(msg.Runes))
+	}
+}
+
+// setQuery replaces the search query and refilters from the top of the list
+func (m *BubbleteaModel) setQuery(query string) {
+	m.SearchQuery = query
+	m.FilterOptions()
+	m.Cursor = 0
+	m.scrollToCursor()
+}
+
+// selectCursor records the highlighted option as the selection and quits
+func (m *BubbleteaModel) selectCursor() tea.Cmd {
+	if len(m.FilteredOptions) == 0 {
+		return nil
+	}
+
+	// Find the original option index that corresponds to the filtered selection
+	selectedOption := m.FilteredOptions[m.Cursor]
+	for i, opt := range m.Options {
+		if opt == selectedOption {
+			m.Selected = i
+			break
+		}
+	}
+	return tea.Quit
+}
+
+// previewVisible reports whether the preview pane fits and is enabled
+func (m *BubbleteaModel) previewVisible() bool {
+	return m.ShowPreview && m.Width >= previewMinWidth
+}
+
+// highlightedPath returns the repo path under the cursor, or an empty string
+func (m *BubbleteaModel) highlightedPath() string {
+	if m.Cursor < 0 || m.Cursor >= len(m.FilteredOptions) {
+		return ""
+	}
+	return m.DirMap[m.FilteredOptions[m.Cursor]]
+}
+
+// requestPreview starts loading the highlighted repo's preview unless it is cached or already loading
+func (m *BubbleteaModel) requestPreview() tea.Cmd {
+	if !m.previewVisible() {
+		return nil
+	}
+	path := m.highlightedPath()
+	if path == "" || m.Previews[path] != nil || m.previewsLoading[path] {
+		return nil
+	}
+
+	if m.Previews == nil {
+		m.Previews = make(map[string]*RepoPreview)
+	}
+	if m.previewsLoading == nil {
+		m.previewsLoading = make(map[string]bool)
+	}
+	m.previewsLoading[path] = true
+	return loadPreview(path)
+}
+
+// withPreview places the preview pane for the highlighted repo to the right of the list view
+func (m *BubbleteaModel) withPreview(list string) string {
+	if !m.previewVisible() {
+		return list
+	}
+
+	listWidth := m.listWidth()
+	previewWidth := m.Width - listWidth - 1 // the border takes a column
+
+	var pane string
+	if preview := m.Previews[m.highlightedPath()]; preview != nil {
+		pane = preview.render(previewWidth, m.Height-1)
+	} else {
+		pane = previewStyle().Width(previewWidth).Render(theme.Muted.Render("Loading…"))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, lipgloss.NewStyle().Width(listWidth).Render(list), pane)
 }
 
 // View is the bubbletea view function that renders the UI
 func (m *BubbleteaModel) View() string {
-	s := "Select a repository:"
+	title := "Select a repository:"
+	if m.Title != "" {
+		title = m.Title
+	}
+	s := theme.Title.Render(title)
 
-	// Show search box if enabled
+	// Show search box if enabled, with a text cursor while typing
 	if m.ShowSearch {
-		s += fmt.Sprintf("\nSearch: %s", m.SearchQuery)
+		textCursor := "▊"
+		if m.navigating() {
+			textCursor = ""
+		}
+		s += fmt.Sprintf("\nSearch: %s%s", m.SearchQuery, textCursor)
+		if m.PickerMode == config.PickerModeVim {
+			if m.Normal {
+				s += theme.Muted.Render("  -- NORMAL --")
+			} else {
+				s += theme.Muted.Render("  -- INSERT --")
+			}
+		}
 	}
 
 	s += "\n\n"
 
 	if len(m.FilteredOptions) == 0 {
-		s += "No matching repositories found.\n"
+		s += theme.Muted.Render("No matching repositories found.") + "\n"
 	} else {
-		for i, option := range m.FilteredOptions {
-			cursor := " "
-			if m.Cursor == i {
-				cursor = ">"
-			}
+		// Only render the rows that fit, with indicators for what's scrolled off
+		start, end := 0, len(m.FilteredOptions)
+		if rows := m.visibleRows(); rows > 0 {
+			start = m.Offset
+			end = min(start+rows, len(m.FilteredOptions))
+		}
+
+		if start > 0 {
+			s += theme.Muted.Render(fmt.Sprintf("  ↑ %d more", start)) + "\n"
+		}
+
+		for i := start; i < end; i++ {
+			option := m.FilteredOptions[i]
 
 			configIndicator := ""
 			if m.ShowConfigIndicator && m.ConfiguredRepos[option] {
-				configIndicator = " [configured]"
+				configIndicator = theme.Configured.Render(" [configured]")
 			}
+			sessionIndicator := ""
+			if session, ok := m.session(option); ok {
+				sessionIndicator = sessionLabel(session)
+			}
+
+			// The cursor row is drawn in the cursor color, matched characters in the match color
+			base := lipgloss.NewStyle()
+			if m.Cursor == i {
+				base = theme.Cursor
+			}
+			var positions []int
+			if i < len(m.MatchPositions) {
+				positions = m.MatchPositions[i]
+			}
+			label := highlightMatches(option, positions, theme.Match, base)
+
+			s += fmt.Sprintf("%s %s%s%s\n", cursorMarker(m.Cursor == i), label, sessionIndicator, configIndicator)
+		}
 
-			s += fmt.Sprintf("%s %s%s\n", cursor, option, configIndicator)
+		if end < len(m.FilteredOptions) {
+			s += theme.Muted.Render(fmt.Sprintf("  ↓ %d more", len(m.FilteredOptions)-end)) + "\n"
 		}
 	}
 
-	s += "\nPress q to quit."
-	if m.ShowSearch {
-		s += " Type to search."
+	// No trailing newline: bubbletea would count it as another line
+	s += "\n" + m.footer()
+
+	// Cut long lines rather than let them wrap, so each row takes exactly the one line budgeted
+	if width := m.listWidth(); width > 0 {
+		s = lipgloss.NewStyle().MaxWidth(width).Render(s)
+	}
+	return m.withPreview(s)
+}
+
+// sessionLabel renders the marker for a repo with a running session, e.g. " ● 3 windows, attached"
+func sessionLabel(session tmux.Session) string {
+	details := fmt.Sprintf("%d windows", session.Windows)
+	if session.Windows == 1 {
+		details = "1 window"
+	}
+	if session.Attached {
+		details += ", attached"
+	}
+	return theme.Configured.Render(" ●") + theme.Muted.Render(" "+details)
+}
+
+// session returns the running session for option, named as tmux names sessions
+func (m *BubbleteaModel) session(option string) (tmux.Session, bool) {
+	session, ok := m.Sessions[tmux.SessionName(option)]
+	return session, ok
+}
+
+// sortRunningFirst moves options with a running session to the top, keeping their order otherwise
+func (m *BubbleteaModel) sortRunningFirst() {
+	options := append([]string(nil), m.Options...)
+	sort.SliceStable(options, func(a, b int) bool {
+		_, runningA := m.session(options[a])
+		_, runningB := m.session(options[b])
+		return runningA && !runningB
+	})
+	m.Options = options
+	m.FilteredOptions = options
+}
+
+// listWidth returns the width the list is drawn in: half the terminal beside the preview,
+// otherwise all of it (0 before the terminal size is known)
+func (m *BubbleteaModel) listWidth() int {
+	if m.previewVisible() {
+		return m.Width / 2
 	}
-	s += "\n"
-	return s
+	return m.Width
 }
 
-// InitializeModel initializes the bubbletea model
+// footer renders the help footer wrapped to the list width, so its height can be budgeted
+func (m *BubbleteaModel) footer() string {
+	footer := m.helpView()
+	if width := m.listWidth(); width > 0 {
+		footer = lipgloss.NewStyle().Width(width).Render(footer)
+	}
+	return footer
+}
+
+// helpView renders the help footer for the current mode from the active bindings
+func (m *BubbleteaModel) helpView() string {
+	keys := m.activeKeys()
+	if m.navigating() {
+		bindings := []key.Binding{keys.Up, keys.Down, keys.Select}
+		if m.ShowSearch {
+			bindings = append(bindings, keys.Search)
+		}
+		return helpView(append(bindings, keys.Quit)...)
+	}
+
+	cancel := keys.Cancel
+	if m.PickerMode == config.PickerModeVim {
+		cancel.SetHelp(cancel.Help().Key, "normal mode")
+	} else {
+		cancel.SetHelp(cancel.Help().Key, "clear/quit")
+	}
+	return theme.Muted.Render("type to search • ") + helpView(keys.Up, keys.Down, keys.PageUp, keys.PageDown, keys.Select, keys.ClearSearch, cancel)
+}
+
+// InitializeModel initializes the bubbletea model, marking repos that have a running tmux session
+// With ui.sort_running_first those repos are moved to the top, so Selected indexes the model's
+// Options rather than the given options.
 func InitializeModel(options []string, dirMap map[string]string) BubbleteaModel {
-	return InitializeRepoSelectorModel(options, dirMap, false)
+	model := InitializeRepoSelectorModel(options, dirMap, false)
+
+	// Without tmux or a tmux server there is nothing to mark
+	if sessions, err := tmux.ListSessions(); err == nil {
+		model.Sessions = sessions
+		if settings.SortRunningFirstEnabled() {
+			model.sortRunningFirst()
+		}
+	}
+	return model
 }
 
 // InitializeRepoSelectorModel initializes the bubbletea model with optional config indicators
@@ -157,14 +464,19 @@ func InitializeRepoSelectorModel(options []string, dirMap map[string]string, sho
 	}
 
 	return BubbleteaModel{
-		Options:            options,
-		FilteredOptions:    options,
-		Cursor:             0,
-		Selected:           -1,
-		DirMap:             dirMap,
-		SearchQuery:        "",
-		ShowSearch:         showSearch,
+		Options:             options,
+		FilteredOptions:     options,
+		Cursor:              0,
+		Selected:            -1,
+		DirMap:              dirMap,
+		SearchQuery:         "",
+		ShowSearch:          showSearch,
 		ShowConfigIndicator: showConfigIndicator,
-		ConfiguredRepos:    configuredRepos,
+		ConfiguredRepos:     configuredRepos,
+		ShowPreview:         dirMap != nil,
+		PickerMode:          settings.PickerModeOrDefault(),
+		Keys:                keymap,
+		Previews:            make(map[string]*RepoPreview),
+		previewsLoading:     make(map[string]bool),
 	}
 }
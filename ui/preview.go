@@ -0,0 +1,185 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	config "github.com/Haptic-Labs/tmux-sessionizer/config"
+	git "github.com/Haptic-Labs/tmux-sessionizer/git"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	previewCommits     = 5  // latest commits shown
+	previewReadmeLines = 6  // non-blank README lines shown
+	previewFiles       = 20 // top-level entries shown before "N more"
+	previewMinWidth    = 80 // narrower terminals hide the preview pane
+)
+
+// previewStyle frames the preview pane
+func previewStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(theme.Muted.GetForeground()).
+		PaddingLeft(1)
+}
+
+// RepoPreview holds the details shown for the highlighted repository
+type RepoPreview struct {
+	Path    string
+	Branch  string
+	Commits []string
+	Readme  []string
+	Files   []string
+	Config  string // which config would apply, e.g. "rule ~/work/** -> client"
+}
+
+// previewLoadedMsg delivers a preview loaded in the background
+type previewLoadedMsg struct {
+	Path    string
+	Preview *RepoPreview
+}
+
+// loadPreview returns a command that gathers a repository's preview off the UI goroutine
+func loadPreview(path string) tea.Cmd {
+	return func() tea.Msg {
+		return previewLoadedMsg{Path: path, Preview: buildPreview(path)}
+	}
+}
+
+// buildPreview collects preview details; missing pieces are left empty
+func buildPreview(path string) *RepoPreview {
+	preview := &RepoPreview{
+		Path:   path,
+		Branch: git.CurrentBranch(path),
+		Readme: readmeExcerpt(path),
+		Files:  topLevelFiles(path),
+		Config: describeResolution(config.ResolveConfig(path)),
+	}
+	if commits, err := git.RecentCommits(path, previewCommits); err == nil {
+		preview.Commits = commits
+	}
+	return preview
+}
+
+// readmeExcerpt returns the first non-blank lines of the repository's README
+func readmeExcerpt(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(strings.ToLower(entry.Name()), "readme") {
+			continue
+		}
+
+		file, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil
+		}
+		defer file.Close()
+
+		var lines []string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() && len(lines) < previewReadmeLines {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return lines
+	}
+	return nil
+}
+
+// topLevelFiles lists the repository's top-level entries, directories first
+func topLevelFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var dirs, files []string
+	for _, entry := range entries {
+		switch {
+		case entry.Name() == ".git":
+			continue
+		case entry.IsDir():
+			dirs = append(dirs, entry.Name()+"/")
+		default:
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(dirs)
+	sort.Strings(files)
+
+	names := append(dirs, files...)
+	if len(names) > previewFiles {
+		names = append(names[:previewFiles], fmt.Sprintf("… %d more", len(names)-previewFiles))
+	}
+	return names
+}
+
+// describeResolution summarizes which config would apply to a repository
+func describeResolution(resolution *config.Resolution) string {
+	var description string
+	switch {
+	case resolution.Source == config.SourceRule && resolution.Rule != nil:
+		description = fmt.Sprintf("rule %s", resolution.Rule.Rule)
+	case resolution.Source == config.SourceDetected:
+		description = fmt.Sprintf("detected %s project", resolution.ProjectType)
+	case resolution.Path != "":
+		description = fmt.Sprintf("%s (%s)", resolution.Source, resolution.Path)
+	default:
+		description = string(resolution.Source)
+	}
+
+	if inTree := resolution.InTree; inTree != nil && !inTree.Trusted {
+		description += "; untrusted .tmux-sessionizer config skipped"
+	}
+	return description
+}
+
+// render draws the preview within width columns and at most height lines (0 for no limit)
+func (p *RepoPreview) render(width int, height int) string {
+	// Leave room for the border and padding
+	textWidth := width - 2
+	fit := func(line string) string {
+		runes := []rune(line)
+		if len(runes) > textWidth {
+			return string(runes[:max(textWidth-1, 0)]) + "…"
+		}
+		return line
+	}
+
+	var lines []string
+	section := func(heading string, body []string) {
+		if len(body) == 0 {
+			return
+		}
+		lines = append(lines, "", theme.Title.Render(heading))
+		for _, line := range body {
+			lines = append(lines, fit("  "+line))
+		}
+	}
+
+	lines = append(lines, theme.Title.Render(fit(p.Path)))
+	if p.Branch != "" {
+		lines = append(lines, fit("Branch: "+p.Branch))
+	}
+	lines = append(lines, fit("Config: "+p.Config))
+	section("Recent commits", p.Commits)
+	section("README", p.Readme)
+	section("Files", p.Files)
+
+	if height > 0 && len(lines) > height {
+		lines = lines[:height]
+	}
+	return previewStyle().Width(width).Render(strings.Join(lines, "\n"))
+}
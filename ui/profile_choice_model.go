@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ProfileChoiceModel lets the user pick a layout profile for a new session
+type ProfileChoiceModel struct {
+	RepoName string
+	Options  []string
+	Cursor   int
+	Selected int
+	Keys     KeyMap // Key bindings, from the user's ui.keymap
+}
+
+// InitializeProfileChoiceModel creates a new profile choice model
+// The cursor starts on the first option, which is expected to be the default profile
+func InitializeProfileChoiceModel(repoName string, profiles []string) ProfileChoiceModel {
+	return ProfileChoiceModel{
+		RepoName: repoName,
+		Options:  profiles,
+		Cursor:   0,
+		Selected: -1,
+		Keys:     keymap,
+	}
+}
+
+// Init is the bubbletea initialization function
+func (m *ProfileChoiceModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles user input
+func (m *ProfileChoiceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c" || key.Matches(msg, m.Keys.Quit, m.Keys.Cancel):
+			m.Selected = -1
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Up):
+			if m.Cursor > 0 {
+				m.Cursor--
+			}
+		case key.Matches(msg, m.Keys.Down):
+			if m.Cursor < len(m.Options)-1 {
+				m.Cursor++
+			}
+		case key.Matches(msg, m.Keys.Select):
+			m.Selected = m.Cursor
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// View renders the UI
+func (m *ProfileChoiceModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(theme.Title.Render(fmt.Sprintf("Choose a layout profile for %s:", m.RepoName)) + "\n\n")
+
+	for i, option := range m.Options {
+		suffix := ""
+		if i == 0 {
+			suffix = " (default)"
+		}
+		row := option + suffix
+		if m.Cursor == i {
+			row = theme.Cursor.Render(row)
+		}
+		s.WriteString(cursorMarker(m.Cursor == i) + " " + row + "\n")
+	}
+
+	s.WriteString("\n" + helpView(m.Keys.Up, m.Keys.Down, m.Keys.Select, m.Keys.Quit) + "\n")
+
+	return s.String()
+}
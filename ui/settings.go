@@ -0,0 +1,18 @@
+package ui
+
+import (
+	config "github.com/Haptic-Labs/tmux-sessionizer/config"
+)
+
+// settings holds the user's UI preferences, read by the Initialize* functions
+var settings config.UIConfig
+
+// ApplySettings sets the UI preferences from the global config's ui section
+// Call it before initializing any models; a nil section keeps the defaults.
+func ApplySettings(ui *config.UIConfig) {
+	if ui != nil {
+		settings = *ui
+	}
+	keymap = DefaultKeyMap().withOverrides(settings.Keymap)
+	theme = newTheme(settings.Theme)
+}
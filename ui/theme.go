@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"os"
+
+	config "github.com/Haptic-Labs/tmux-sessionizer/config"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// palette holds a built-in theme's colors, as ANSI 256-color numbers
+type palette struct {
+	Title      string
+	Cursor     string
+	Match      string
+	Configured string
+	Error      string
+	Muted      string
+}
+
+// Built-in palettes
+var (
+	darkPalette  = palette{Title: "75", Cursor: "212", Match: "42", Configured: "78", Error: "203", Muted: "244"}
+	lightPalette = palette{Title: "25", Cursor: "162", Match: "28", Configured: "29", Error: "160", Muted: "243"}
+)
+
+// Theme holds the styles every view renders with
+type Theme struct {
+	Title      lipgloss.Style // headings
+	Cursor     lipgloss.Style // the highlighted row
+	Match      lipgloss.Style // characters matched by the search
+	Configured lipgloss.Style // [configured] indicators and success messages
+	Error      lipgloss.Style // errors and warnings
+	Muted      lipgloss.Style // help text, scroll indicators and borders
+	Help       help.Styles    // the help footer
+}
+
+// newTheme builds the theme selected by cfg (nil for the default)
+// Colors are dropped when $NO_COLOR is set or the "none" theme is chosen; bold and
+// underline still mark the cursor and matches.
+func newTheme(cfg *config.ThemeConfig) Theme {
+	if cfg == nil {
+		cfg = &config.ThemeConfig{}
+	}
+
+	if os.Getenv("NO_COLOR") != "" || cfg.Name == config.ThemeNone {
+		plain := lipgloss.NewStyle()
+		return Theme{
+			Title:      plain.Bold(true),
+			Cursor:     plain.Bold(true),
+			Match:      plain.Underline(true),
+			Configured: plain,
+			Error:      plain.Bold(true),
+			Muted:      plain,
+			Help: help.Styles{
+				ShortKey:       plain,
+				ShortDesc:      plain,
+				ShortSeparator: plain,
+				Ellipsis:       plain,
+			},
+		}
+	}
+
+	// color picks one role's color: a config override, the chosen palette's, or for
+	// the auto theme whichever palette suits the terminal background
+	color := func(override string, light string, dark string) lipgloss.TerminalColor {
+		switch {
+		case override != "":
+			return lipgloss.Color(override)
+		case cfg.Name == config.ThemeDark:
+			return lipgloss.Color(dark)
+		case cfg.Name == config.ThemeLight:
+			return lipgloss.Color(light)
+		}
+		return lipgloss.AdaptiveColor{Light: light, Dark: dark}
+	}
+	titleColor := color(cfg.Title, lightPalette.Title, darkPalette.Title)
+	cursorColor := color(cfg.Cursor, lightPalette.Cursor, darkPalette.Cursor)
+	matchColor := color(cfg.Match, lightPalette.Match, darkPalette.Match)
+	configuredColor := color(cfg.Configured, lightPalette.Configured, darkPalette.Configured)
+	errorColor := color(cfg.Error, lightPalette.Error, darkPalette.Error)
+	mutedColor := color(cfg.Muted, lightPalette.Muted, darkPalette.Muted)
+
+	muted := lipgloss.NewStyle().Foreground(mutedColor)
+	return Theme{
+		Title:      lipgloss.NewStyle().Bold(true).Foreground(titleColor),
+		Cursor:     lipgloss.NewStyle().Bold(true).Foreground(cursorColor),
+		Match:      lipgloss.NewStyle().Bold(true).Foreground(matchColor),
+		Configured: lipgloss.NewStyle().Foreground(configuredColor),
+		Error:      lipgloss.NewStyle().Bold(true).Foreground(errorColor),
+		Muted:      muted,
+		Help: help.Styles{
+			ShortKey:       lipgloss.NewStyle().Foreground(titleColor),
+			ShortDesc:      muted,
+			ShortSeparator: muted,
+			Ellipsis:       muted,
+		},
+	}
+}
+
+// theme is the theme views render with; see ApplySettings
+var theme = newTheme(nil)
+
+// cursorMarker renders the row prefix, highlighted for the cursor row
+func cursorMarker(isCursor bool) string {
+	if isCursor {
+		return theme.Cursor.Render(">")
+	}
+	return " "
+}